@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rasmussecher/pokedex/internal/battle"
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+// maxKnownMoves is how many moves a Pokemon can use in battle.
+const maxKnownMoves = 4
+
+func commandBattle(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) != 1 {
+		return nil, errors.New("usage: battle <pokemon_name>")
+	}
+	if cfg.battle != nil {
+		return nil, errors.New("you are already in a battle")
+	}
+	wild := cfg.encounter
+	if wild == nil {
+		return nil, errors.New("there is no wild Pokemon to battle, use \"encounter\" to look for one")
+	}
+	owned, box, err := cfg.collection.find(params[0])
+	if err != nil {
+		return nil, err
+	}
+	if box != 0 {
+		return nil, fmt.Errorf("%s is in box %d, withdraw it to your party first", owned.name(), box)
+	}
+
+	opponentPokemon, err := cfg.pokeapiClient.GetPokemonContext(ctx, wild.Pokemon)
+	if err != nil {
+		return nil, err
+	}
+	playerStats, err := owned.stats(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	player, err := newCombatant(ctx, cfg, owned.Pokemon, owned.Level, playerStats)
+	if err != nil {
+		return nil, err
+	}
+	opponentStats, err := wildStats(ctx, cfg, wild, opponentPokemon)
+	if err != nil {
+		return nil, err
+	}
+	opponent, err := newCombatant(ctx, cfg, opponentPokemon, wild.Level, opponentStats)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load both sides' types up front so damage calculation never has to
+	// go to the network mid-turn.
+	for _, c := range []*battle.Combatant{player, opponent} {
+		if _, err := cfg.typeChart.Defense(ctx, c.Types...); err != nil {
+			return nil, err
+		}
+	}
+	effectiveness := func(attacking string, defending []string) float64 {
+		m, err := cfg.typeChart.Multiplier(context.Background(), attacking, defending...)
+		if err != nil {
+			return 1
+		}
+		return m
+	}
+
+	cfg.battle = battle.New(player, opponent, cfg.rng, effectiveness)
+	cfg.battler = owned.ID
+	return newBattleResult(cfg.battle, nil, "started"), nil
+}
+
+func commandAttack(ctx context.Context, cfg *config, params []string) (any, error) {
+	b := cfg.battle
+	if b == nil {
+		return nil, errors.New("you are not in a battle")
+	}
+
+	index := -1
+	if len(params) > 0 {
+		index = findMove(b.Player, params[0])
+		if index < 0 {
+			return nil, fmt.Errorf("%s does not know %s", b.Player.Name, params[0])
+		}
+	}
+
+	events, err := b.Turn(index)
+	if errors.Is(err, battle.ErrBadMove) && index == -1 {
+		return nil, errors.New("choose a move: attack <move_name|number>")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := "ongoing"
+	switch {
+	case b.Opponent.Fainted():
+		outcome = "won"
+	case b.Player.Fainted():
+		outcome = "lost"
+		cfg.battle = nil
+	}
+	res := newBattleResult(b, events, outcome)
+	if outcome != "won" {
+		return res, nil
+	}
+
+	wild := cfg.encounter
+	cfg.battle = nil
+	cfg.encounter = nil
+	defeated, err := cfg.pokeapiClient.GetPokemonContext(ctx, wild.Pokemon)
+	if err != nil {
+		return nil, err
+	}
+	res.Experience, err = gainExperience(ctx, cfg, cfg.battler, defeated, wild.Level)
+	if err != nil {
+		return nil, err
+	}
+	autosave(cfg)
+	return res, nil
+}
+
+func commandFlee(ctx context.Context, cfg *config, params []string) (any, error) {
+	if cfg.battle == nil && cfg.encounter == nil {
+		return nil, errors.New("there is nothing to flee from")
+	}
+	cfg.battle = nil
+	cfg.encounter = nil
+	notice(cfg, "Got away safely!\n")
+	return nil, nil
+}
+
+// findMove matches a move by name or by its 1-based number.
+func findMove(c *battle.Combatant, name string) int {
+	if n, err := strconv.Atoi(name); err == nil && n >= 1 && n <= len(c.Moves) {
+		return n - 1
+	}
+	for i, m := range c.Moves {
+		if m.Move.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func newCombatant(ctx context.Context, cfg *config, p pokeapi.Pokemon, level int, stats battle.Stats) (*battle.Combatant, error) {
+	moves, err := knownMoves(ctx, cfg, p, level)
+	if err != nil {
+		return nil, err
+	}
+	types := []string{}
+	for _, t := range p.Types {
+		types = append(types, t.Type.Name)
+	}
+	return battle.NewCombatant(p.Name, level, types, stats, moves), nil
+}
+
+// knownMoves picks the most recent damaging moves learned by level-up at or
+// below level, the way wild Pokemon get their moves in the games.
+func knownMoves(ctx context.Context, cfg *config, p pokeapi.Pokemon, level int) ([]battle.Move, error) {
+	type candidate struct {
+		name  string
+		level int
+	}
+	candidates := []candidate{}
+	for _, m := range p.Moves {
+		learnedAt := -1
+		for _, d := range m.VersionGroupDetails {
+			if d.MoveLearnMethod.Name != "level-up" || d.LevelLearnedAt > level {
+				continue
+			}
+			if learnedAt < 0 || d.LevelLearnedAt < learnedAt {
+				learnedAt = d.LevelLearnedAt
+			}
+		}
+		if learnedAt >= 0 {
+			candidates = append(candidates, candidate{name: m.Move.Name, level: learnedAt})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].level > candidates[j].level
+	})
+
+	moves := []battle.Move{}
+	for _, c := range candidates {
+		if len(moves) == maxKnownMoves {
+			break
+		}
+		move, err := cfg.pokeapiClient.GetMoveContext(ctx, c.name)
+		if err != nil {
+			return nil, err
+		}
+		if bm := toBattleMove(move); bm.DamageClass != battle.Status && bm.Power > 0 {
+			moves = append(moves, bm)
+		}
+	}
+	for i, j := 0, len(moves)-1; i < j; i, j = i+1, j-1 {
+		moves[i], moves[j] = moves[j], moves[i]
+	}
+	return moves, nil
+}
+
+func toBattleMove(m pokeapi.Move) battle.Move {
+	bm := battle.Move{
+		Name:        m.Name,
+		Type:        m.Type.Name,
+		DamageClass: m.DamageClass.Name,
+		Priority:    m.Priority,
+	}
+	if m.Power != nil {
+		bm.Power = *m.Power
+	}
+	if m.Accuracy != nil {
+		bm.Accuracy = *m.Accuracy
+	}
+	if m.PP != nil {
+		bm.PP = *m.PP
+	}
+	return bm
+}
+
+type combatantSummary struct {
+	Name  string   `json:"name"`
+	Level int      `json:"level"`
+	HP    int      `json:"hp"`
+	MaxHP int      `json:"max_hp"`
+	Moves []string `json:"moves"`
+}
+
+type battleResult struct {
+	Outcome    string           `json:"outcome"`
+	Turn       int              `json:"turn"`
+	Player     combatantSummary `json:"player"`
+	Opponent   combatantSummary `json:"opponent"`
+	Events     []battle.Event   `json:"events"`
+	Experience *experienceGain  `json:"experience,omitempty"`
+}
+
+func newBattleResult(b *battle.Battle, events []battle.Event, outcome string) battleResult {
+	summary := func(c *battle.Combatant) combatantSummary {
+		s := combatantSummary{Name: c.Name, Level: c.Level, HP: c.HP, MaxHP: c.Stats.HP, Moves: []string{}}
+		for _, m := range c.Moves {
+			s.Moves = append(s.Moves, fmt.Sprintf("%s (%s, %d/%d PP)", m.Move.Name, m.Move.Type, m.PP, m.Move.PP))
+		}
+		return s
+	}
+	if events == nil {
+		events = []battle.Event{}
+	}
+	return battleResult{
+		Outcome:  outcome,
+		Turn:     b.Turns,
+		Player:   summary(b.Player),
+		Opponent: summary(b.Opponent),
+		Events:   events,
+	}
+}
+
+func (r battleResult) WriteText(w io.Writer) error {
+	if r.Outcome == "started" {
+		fmt.Fprintf(w, "Go, %s!\n", r.Player.Name)
+	}
+	for _, e := range r.Events {
+		fmt.Fprintf(w, "%s used %s!\n", e.Attacker, e.Move)
+		switch {
+		case e.Missed:
+			fmt.Fprintf(w, "  The attack missed!\n")
+			continue
+		case e.Effectiveness == 0:
+			fmt.Fprintf(w, "  It doesn't affect %s...\n", e.Defender)
+			continue
+		}
+		if e.Critical {
+			fmt.Fprintf(w, "  A critical hit!\n")
+		}
+		if e.Effectiveness > 1 {
+			fmt.Fprintf(w, "  It's super effective!\n")
+		} else if e.Effectiveness < 1 {
+			fmt.Fprintf(w, "  It's not very effective...\n")
+		}
+		fmt.Fprintf(w, "  %s took %d damage.\n", e.Defender, e.Damage)
+		if e.Fainted {
+			fmt.Fprintf(w, "  %s fainted!\n", e.Defender)
+		}
+	}
+
+	fmt.Fprintf(w, "%s\n%s\n", hpLine(r.Opponent), hpLine(r.Player))
+	switch r.Outcome {
+	case "won":
+		fmt.Fprintf(w, "You defeated the wild %s!\n", r.Opponent.Name)
+		if r.Experience != nil {
+			r.Experience.WriteText(w)
+		}
+	case "lost":
+		fmt.Fprintf(w, "%s can't fight any more, the battle is over.\n", r.Player.Name)
+	default:
+		fmt.Fprintf(w, "Moves:\n")
+		for i, m := range r.Player.Moves {
+			fmt.Fprintf(w, "  %d. %s\n", i+1, m)
+		}
+	}
+	return nil
+}
+
+func (r battleResult) Header() []string {
+	return []string{"attacker", "move", "defender", "damage", "critical", "effectiveness", "missed", "fainted"}
+}
+
+func (r battleResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, e := range r.Events {
+		rows = append(rows, []string{
+			e.Attacker, e.Move, e.Defender, strconv.Itoa(e.Damage), strconv.FormatBool(e.Critical),
+			formatMultiplier(e.Effectiveness), strconv.FormatBool(e.Missed), strconv.FormatBool(e.Fainted),
+		})
+	}
+	return rows
+}
+
+func hpLine(c combatantSummary) string {
+	const width = 20
+	filled := 0
+	if c.MaxHP > 0 {
+		filled = c.HP * width / c.MaxHP
+	}
+	if c.HP > 0 && filled == 0 {
+		filled = 1
+	}
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", width-filled)
+	return fmt.Sprintf("%-12s lv %-3d [%s] %d/%d", c.Name, c.Level, bar, c.HP, c.MaxHP)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+	"github.com/rasmussecher/pokedex/internal/pokecache"
+)
+
+const cacheUsage = "usage: cache stats | clear | list [prefix] | evict <url>"
+
+func commandCache(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) == 0 {
+		return nil, errors.New(cacheUsage)
+	}
+	cache := cfg.pokeapiClient.Cache()
+	sub, args := params[0], params[1:]
+
+	switch {
+	case sub == "stats" && len(args) == 0:
+		return cacheStatsResult{Cache: cache.Stats(), Client: cfg.pokeapiClient.Stats()}, nil
+	case sub == "clear" && len(args) == 0:
+		n, err := cache.Clear()
+		if err != nil {
+			return nil, fmt.Errorf("clearing the cache: %w", err)
+		}
+		return cacheClearResult{Cleared: n}, nil
+	case sub == "list" && len(args) <= 1:
+		prefix := ""
+		if len(args) == 1 {
+			prefix = cacheKey(cfg, args[0])
+		}
+		return cacheListResult{Keys: cache.Keys(prefix)}, nil
+	case sub == "evict" && len(args) == 1:
+		key := cacheKey(cfg, args[0])
+		if !cache.Remove(key) {
+			return nil, fmt.Errorf("%s is not cached", key)
+		}
+		return cacheEvictResult{Evicted: key}, nil
+	}
+	return nil, errors.New(cacheUsage)
+}
+
+// cacheKey turns a path such as pokemon/pikachu into the URL it is cached
+// under. Full URLs are used as they are.
+func cacheKey(cfg *config, s string) string {
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		return s
+	}
+	return cfg.pokeapiClient.Endpoint(strings.TrimLeft(s, "/"))
+}
+
+type cacheStatsResult struct {
+	Cache  pokecache.Stats `json:"cache"`
+	Client pokeapi.Stats   `json:"client"`
+}
+
+func (r cacheStatsResult) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Entries:     %d", r.Cache.Entries)
+	if r.Cache.MaxEntries > 0 {
+		fmt.Fprintf(w, "/%d", r.Cache.MaxEntries)
+	}
+	fmt.Fprintf(w, "\nSize:        %d bytes", r.Cache.Bytes)
+	if r.Cache.MaxBytes > 0 {
+		fmt.Fprintf(w, "/%d", r.Cache.MaxBytes)
+	}
+	fmt.Fprintf(w, "\nHits:        %d (%.1f%%)\n", r.Cache.Hits, r.Cache.HitRate()*100)
+	fmt.Fprintf(w, "Misses:      %d\n", r.Cache.Misses)
+	fmt.Fprintf(w, "Evictions:   %d\n", r.Cache.Evictions)
+	fmt.Fprintf(w, "Expirations: %d\n", r.Cache.Expirations)
+	fmt.Fprintf(w, "Requests:    %d\n", r.Client.Requests)
+	fmt.Fprintf(w, "Coalesced:   %d\n", r.Client.Coalesced)
+	fmt.Fprintf(w, "Retries:     %d\n", r.Client.Retries)
+	fmt.Fprintf(w, "Unmodified:  %d\n", r.Client.NotModified)
+	fmt.Fprintf(w, "Stale:       %d\n", r.Client.Stale)
+	return nil
+}
+
+func (r cacheStatsResult) Header() []string {
+	return []string{"stat", "value"}
+}
+
+func (r cacheStatsResult) Rows() [][]string {
+	return [][]string{
+		{"entries", strconv.Itoa(r.Cache.Entries)},
+		{"bytes", strconv.FormatInt(r.Cache.Bytes, 10)},
+		{"max_entries", strconv.Itoa(r.Cache.MaxEntries)},
+		{"max_bytes", strconv.FormatInt(r.Cache.MaxBytes, 10)},
+		{"hits", strconv.FormatInt(r.Cache.Hits, 10)},
+		{"misses", strconv.FormatInt(r.Cache.Misses, 10)},
+		{"evictions", strconv.FormatInt(r.Cache.Evictions, 10)},
+		{"expirations", strconv.FormatInt(r.Cache.Expirations, 10)},
+		{"requests", strconv.FormatInt(r.Client.Requests, 10)},
+		{"coalesced", strconv.FormatInt(r.Client.Coalesced, 10)},
+		{"retries", strconv.FormatInt(r.Client.Retries, 10)},
+		{"not_modified", strconv.FormatInt(r.Client.NotModified, 10)},
+		{"stale", strconv.FormatInt(r.Client.Stale, 10)},
+	}
+}
+
+type cacheListResult struct {
+	Keys []string `json:"keys"`
+}
+
+func (r cacheListResult) WriteText(w io.Writer) error {
+	if len(r.Keys) == 0 {
+		_, err := fmt.Fprintln(w, "Nothing cached in memory")
+		return err
+	}
+	for _, key := range r.Keys {
+		fmt.Fprintf(w, " - %s\n", key)
+	}
+	return nil
+}
+
+func (r cacheListResult) Header() []string {
+	return []string{"url"}
+}
+
+func (r cacheListResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, key := range r.Keys {
+		rows = append(rows, []string{key})
+	}
+	return rows
+}
+
+type cacheClearResult struct {
+	Cleared int `json:"cleared"`
+}
+
+func (r cacheClearResult) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Cleared %d cached responses\n", r.Cleared)
+	return err
+}
+
+func (r cacheClearResult) Header() []string {
+	return []string{"cleared"}
+}
+
+func (r cacheClearResult) Rows() [][]string {
+	return [][]string{{strconv.Itoa(r.Cleared)}}
+}
+
+type cacheEvictResult struct {
+	Evicted string `json:"evicted"`
+}
+
+func (r cacheEvictResult) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Evicted %s\n", r.Evicted)
+	return err
+}
+
+func (r cacheEvictResult) Header() []string {
+	return []string{"evicted"}
+}
+
+func (r cacheEvictResult) Rows() [][]string {
+	return [][]string{{r.Evicted}}
+}
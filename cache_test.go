@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+func TestCommandCache(t *testing.T) {
+	client := pokeapi.NewClient(pokeapi.WithBaseURL("https://pokeapi.test/api/v2"))
+	defer client.Close()
+	cfg := &config{pokeapiClient: client}
+	cache := client.Cache()
+	cache.Add("https://pokeapi.test/api/v2/pokemon/pikachu", []byte("{}"))
+	cache.Add("https://pokeapi.test/api/v2/move/thunderbolt", []byte("{}"))
+
+	res, err := commandCache(context.Background(), cfg, []string{"list", "pokemon"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := res.(cacheListResult).Keys; len(keys) != 1 || keys[0] != "https://pokeapi.test/api/v2/pokemon/pikachu" {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	if _, err := commandCache(context.Background(), cfg, []string{"evict", "pokemon/pikachu"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := commandCache(context.Background(), cfg, []string{"evict", "pokemon/pikachu"}); err == nil {
+		t.Errorf("expected evicting an uncached url to fail")
+	}
+
+	res, err = commandCache(context.Background(), cfg, []string{"clear"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := res.(cacheClearResult).Cleared; n != 1 {
+		t.Errorf("Result: %d, does not equal expected: %d", n, 1)
+	}
+
+	for _, params := range [][]string{{}, {"stats", "x"}, {"flush"}} {
+		if _, err := commandCache(context.Background(), cfg, params); err == nil {
+			t.Errorf("expected %v to be rejected", params)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rasmussecher/pokedex/internal/render"
+)
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage:\n")
+	fmt.Fprintf(out, "  pokedex [flags]                       start the interactive Pokedex\n")
+	fmt.Fprintf(out, "  pokedex [flags] <command> [args...]   run a single command and exit\n")
+	fmt.Fprintf(out, "  pokedex [flags] run <file>...         run commands from files, - for stdin\n")
+	fmt.Fprintf(out, "\nCommands are the same as in the interactive Pokedex, see \"pokedex help\".\n")
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// extractOutputFlags lets --json and --output follow the subcommand, as in
+// "pokedex inspect pikachu --json", which the flag package would otherwise
+// leave in the command's parameters.
+func extractOutputFlags(cfg *config, args []string) ([]string, error) {
+	rest := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--json" || arg == "-json":
+			cfg.output = render.JSON
+		case arg == "--output" || arg == "-output":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s needs a format", arg)
+			}
+			i++
+			format, err := render.ParseFormat(args[i])
+			if err != nil {
+				return nil, err
+			}
+			cfg.output = format
+		case strings.HasPrefix(arg, "--output=") || strings.HasPrefix(arg, "-output="):
+			format, err := render.ParseFormat(arg[strings.Index(arg, "=")+1:])
+			if err != nil {
+				return nil, err
+			}
+			cfg.output = format
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, nil
+}
+
+// runOneShot executes a single command given on the command line and
+// returns the process exit status.
+func runOneShot(cfg *config, args []string) int {
+	args, err := extractOutputFlags(cfg, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	input := cleanInput(strings.Join(args, " "))
+	if err := execute(cfg, input); err != nil {
+		fmt.Fprintln(os.Stderr, describeError(err))
+		return 1
+	}
+	autosave(cfg)
+	return 0
+}
+
+func runScriptFiles(cfg *config, paths []string) int {
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "run needs at least one script file, use - for stdin")
+		return 2
+	}
+
+	for _, path := range paths {
+		if path == "-" {
+			if code := runScript(cfg, os.Stdin, "stdin"); code != 0 {
+				return code
+			}
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, describeError(err))
+			return 1
+		}
+		code := runScript(cfg, f, path)
+		f.Close()
+		if code != 0 {
+			return code
+		}
+	}
+	return 0
+}
+
+// runScript executes commands from r line by line, skipping blank lines and
+// # comments, and stops with a non-zero status at the first failure.
+func runScript(cfg *config, r io.Reader, name string) int {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if err := execute(cfg, cleanInput(text)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", name, lineNo, describeError(err))
+			autosave(cfg)
+			return 1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	autosave(cfg)
+	return 0
+}
+
+// execute looks up and runs a cleaned command line, treating unknown
+// commands as errors.
+func execute(cfg *config, input []string) error {
+	if len(input) == 0 {
+		return nil
+	}
+	command, ok := commands[input[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", input[0])
+	}
+	return runCommand(cfg, command, input[1:])
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// commandFlags splits "--name value" and "--name=value" options out of a
+// command's parameters. Names in boolFlags take no value and are set to
+// "true" when present.
+func commandFlags(params []string, boolFlags ...string) ([]string, map[string]string, error) {
+	positional := []string{}
+	flags := map[string]string{}
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		if !strings.HasPrefix(p, "--") {
+			positional = append(positional, p)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(p, "--"), "=")
+		isBool := false
+		for _, b := range boolFlags {
+			if b == name {
+				isBool = true
+			}
+		}
+		switch {
+		case isBool && hasValue:
+			return nil, nil, fmt.Errorf("--%s does not take a value", name)
+		case isBool:
+			value = "true"
+		case !hasValue:
+			if i+1 >= len(params) {
+				return nil, nil, fmt.Errorf("--%s needs a value", name)
+			}
+			i++
+			value = params[i]
+		}
+		flags[name] = value
+	}
+	return positional, flags, nil
+}
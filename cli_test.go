@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rasmussecher/pokedex/internal/render"
+)
+
+func TestRunScript(t *testing.T) {
+	cases := []struct {
+		script string
+		code   int
+	}{
+		{script: "# list what we have\n\npokedex\n", code: 0},
+		{script: "pokedex\nfoo\npokedex\n", code: 1},
+		{script: "inspect pikachu\n", code: 1},
+	}
+
+	for _, c := range cases {
+		cfg := &config{}
+		if code := runScript(cfg, strings.NewReader(c.script), "test"); code != c.code {
+			t.Errorf("Result: %d, does not equal expected: %d for script %q", code, c.code, c.script)
+		}
+	}
+}
+
+func TestFlee(t *testing.T) {
+	cfg := &config{encounter: &wildEncounter{Pokemon: "pikachu"}}
+	if err := execute(cfg, []string{"flee"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.encounter != nil {
+		t.Errorf("expected fleeing to end the encounter")
+	}
+	if err := execute(cfg, []string{"flee"}); err == nil {
+		t.Errorf("expected an error with nothing to flee from")
+	}
+	// "run" is the script subcommand, not a way out of a battle.
+	if _, ok := commands["run"]; ok {
+		t.Errorf("run should not be a REPL command")
+	}
+}
+
+func TestCommandFlags(t *testing.T) {
+	cases := []struct {
+		params     []string
+		positional []string
+		flags      map[string]string
+		err        bool
+	}{
+		{
+			params:     []string{"pikachu", "--version-group", "red-blue", "--method=machine"},
+			positional: []string{"pikachu"},
+			flags:      map[string]string{"version-group": "red-blue", "method": "machine"},
+		},
+		{
+			params:     []string{"--missing", "--region", "kanto"},
+			positional: []string{},
+			flags:      map[string]string{"missing": "true", "region": "kanto"},
+		},
+		{params: []string{"pikachu", "--method"}, err: true},
+		{params: []string{"--missing=yes"}, err: true},
+	}
+
+	for _, c := range cases {
+		positional, flags, err := commandFlags(c.params, "missing")
+		if c.err {
+			if err == nil {
+				t.Errorf("expected an error for %v", c.params)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", c.params, err)
+			continue
+		}
+		if strings.Join(positional, " ") != strings.Join(c.positional, " ") {
+			t.Errorf("Result: %v, does not equal expected: %v", positional, c.positional)
+		}
+		if len(flags) != len(c.flags) {
+			t.Errorf("Result: %v, does not equal expected: %v", flags, c.flags)
+		}
+		for k, v := range c.flags {
+			if flags[k] != v {
+				t.Errorf("Result: %s=%s, does not equal expected: %s=%s", k, flags[k], k, v)
+			}
+		}
+	}
+}
+
+func TestResultsRenderAsCSV(t *testing.T) {
+	results := []any{
+		saveResult{Action: "save", Path: "/tmp/save.json", Caught: 3},
+		evolveResult{Pokemon: "pikachu", EvolvedInto: "raichu"},
+		cacheClearResult{Cleared: 2},
+		cacheEvictResult{Evicted: "https://pokeapi.co/api/v2/pokemon/pikachu"},
+		evolveResult{Pokemon: "eevee", Missing: []evolutionRequirement{{Species: "espeon", Needs: []string{"happiness 160", "day"}}}},
+	}
+
+	for _, res := range results {
+		var buf bytes.Buffer
+		if err := render.Render(&buf, render.CSV, res); err != nil {
+			t.Errorf("unexpected error rendering %T: %v", res, err)
+		}
+	}
+}
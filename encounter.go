@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rasmussecher/pokedex/internal/battle"
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+const defaultEncounterMethod = "walk"
+
+// wildEncounter is the wild Pokemon currently in front of the player.
+type wildEncounter struct {
+	Pokemon string `json:"pokemon"`
+	Level   int    `json:"level"`
+	Area    string `json:"area"`
+	Method  string `json:"method"`
+	Version string `json:"version"`
+	// IVs and Nature stay hidden until the Pokemon is caught.
+	IVs    battle.Stats `json:"-"`
+	Nature string       `json:"-"`
+}
+
+func (r wildEncounter) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "A wild %s (lv %d) appeared!\n", r.Pokemon, r.Level)
+	return err
+}
+
+func (r wildEncounter) Header() []string {
+	return []string{"pokemon", "level", "area", "method", "version"}
+}
+
+func (r wildEncounter) Rows() [][]string {
+	return [][]string{{r.Pokemon, strconv.Itoa(r.Level), r.Area, r.Method, r.Version}}
+}
+
+// encounterSlot is a single way for a Pokemon to appear in an area.
+type encounterSlot struct {
+	Pokemon   string
+	Encounter pokeapi.Encounter
+}
+
+func commandEncounter(ctx context.Context, cfg *config, params []string) (any, error) {
+	if cfg.area == "" {
+		return nil, errors.New("you are not anywhere yet, use \"goto <location>\" first")
+	}
+	if cfg.battle != nil {
+		return nil, errors.New("you are in a battle, \"attack\", \"catch\" or \"flee\"")
+	}
+	method := defaultEncounterMethod
+	if len(params) > 0 {
+		method = params[0]
+	}
+
+	area, err := cfg.pokeapiClient.GetPokemonsForAreaContext(ctx, cfg.area)
+	if err != nil {
+		return nil, err
+	}
+	version := pickVersion(area, cfg.gameVersion)
+	slots := encounterSlots(area, version, encounterTime(time.Now()))
+
+	methodSlots := []encounterSlot{}
+	methods := map[string]bool{}
+	for _, s := range slots {
+		methods[s.Encounter.Method.Name] = true
+		if s.Encounter.Method.Name == method {
+			methodSlots = append(methodSlots, s)
+		}
+	}
+	if len(methodSlots) == 0 {
+		if len(methods) == 0 {
+			return nil, fmt.Errorf("there are no wild Pokemon in %s right now", cfg.area)
+		}
+		return nil, fmt.Errorf("no %s encounters in %s, try one of: %s", method, cfg.area, strings.Join(sortedKeys(methods), ", "))
+	}
+
+	wild, ok := rollEncounter(cfg.rng, methodSlots)
+	if !ok {
+		return nil, fmt.Errorf("there are no wild Pokemon in %s right now", cfg.area)
+	}
+	wild.Area = cfg.area
+	wild.Version = version
+	pokemon, err := cfg.pokeapiClient.GetPokemonContext(ctx, wild.Pokemon)
+	if err != nil {
+		return nil, err
+	}
+	cfg.pokedex.seePokemon(pokemon)
+	wild.IVs = battle.RandomIVs(cfg.rng)
+	wild.Nature, err = randomNature(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.encounter = &wild
+	return wild, nil
+}
+
+// pickVersion returns preferred when the area has encounters for it, and
+// otherwise the first version the area lists.
+func pickVersion(area pokeapi.PokemonEncounterList, preferred string) string {
+	first := ""
+	for _, e := range area.Encounters {
+		for _, v := range e.VersionDetails {
+			if v.Version.Name == preferred {
+				return preferred
+			}
+			if first == "" {
+				first = v.Version.Name
+			}
+		}
+	}
+	return first
+}
+
+// encounterSlots lists the encounters available in version whose
+// conditions hold at the given time of day.
+func encounterSlots(area pokeapi.PokemonEncounterList, version, timeCondition string) []encounterSlot {
+	slots := []encounterSlot{}
+	for _, e := range area.Encounters {
+		for _, v := range e.VersionDetails {
+			if v.Version.Name != version {
+				continue
+			}
+			for _, d := range v.EncounterDetails {
+				if conditionsMet(d.ConditionValues, timeCondition) {
+					slots = append(slots, encounterSlot{Pokemon: e.Pokemon.Name, Encounter: d})
+				}
+			}
+		}
+	}
+	return slots
+}
+
+// conditionsMet reports whether an encounter can happen now. Time of day
+// conditions must match; for the rest, such as swarms or the Poke Radar,
+// only the default "off" state is assumed to hold.
+func conditionsMet(values []pokeapi.NamedAPIResource, timeCondition string) bool {
+	for _, v := range values {
+		switch {
+		case strings.HasPrefix(v.Name, "time-"):
+			if v.Name != timeCondition {
+				return false
+			}
+		case strings.HasSuffix(v.Name, "-no"), strings.HasSuffix(v.Name, "-off"), strings.HasSuffix(v.Name, "-none"):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// rollEncounter picks a slot with probability proportional to its chance
+// and a level uniformly within its range.
+func rollEncounter(rng *rand.Rand, slots []encounterSlot) (wildEncounter, bool) {
+	total := 0
+	for _, s := range slots {
+		total += s.Encounter.Chance
+	}
+	if total <= 0 {
+		return wildEncounter{}, false
+	}
+
+	roll := rng.Intn(total)
+	for _, s := range slots {
+		if roll >= s.Encounter.Chance {
+			roll -= s.Encounter.Chance
+			continue
+		}
+		e := s.Encounter
+		level := e.MinLevel
+		if e.MaxLevel > e.MinLevel {
+			level += rng.Intn(e.MaxLevel - e.MinLevel + 1)
+		}
+		return wildEncounter{Pokemon: s.Pokemon, Level: level, Method: e.Method.Name}, true
+	}
+	return wildEncounter{}, false
+}
+
+func encounterTime(t time.Time) string {
+	switch h := t.Hour(); {
+	case h >= 4 && h < 10:
+		return "time-morning"
+	case h >= 10 && h < 20:
+		return "time-day"
+	default:
+		return "time-night"
+	}
+}
+
+type methodChance struct {
+	Method string `json:"method"`
+	Chance int    `json:"chance"`
+}
+
+type exploreEntry struct {
+	Name     string         `json:"name"`
+	MinLevel int            `json:"min_level"`
+	MaxLevel int            `json:"max_level"`
+	Methods  []methodChance `json:"methods"`
+}
+
+// newExploreResult summarises the encounters of an area for one version,
+// adding up the chances of each Pokemon per encounter method.
+func newExploreResult(area pokeapi.PokemonEncounterList, version string) exploreResult {
+	res := exploreResult{Area: area.Name, Version: version, Pokemon: []exploreEntry{}}
+	for _, e := range area.Encounters {
+		entry := exploreEntry{Name: e.Pokemon.Name, Methods: []methodChance{}}
+		chances := map[string]int{}
+		order := []string{}
+		for _, v := range e.VersionDetails {
+			if v.Version.Name != version {
+				continue
+			}
+			for _, d := range v.EncounterDetails {
+				if entry.MinLevel == 0 || d.MinLevel < entry.MinLevel {
+					entry.MinLevel = d.MinLevel
+				}
+				entry.MaxLevel = max(entry.MaxLevel, d.MaxLevel)
+				if _, ok := chances[d.Method.Name]; !ok {
+					order = append(order, d.Method.Name)
+				}
+				chances[d.Method.Name] += d.Chance
+			}
+		}
+		if len(order) == 0 {
+			continue
+		}
+		for _, m := range order {
+			entry.Methods = append(entry.Methods, methodChance{Method: m, Chance: chances[m]})
+		}
+		res.Pokemon = append(res.Pokemon, entry)
+	}
+	return res
+}
+
+type exploreResult struct {
+	Area    string         `json:"area"`
+	Version string         `json:"version"`
+	Pokemon []exploreEntry `json:"pokemon"`
+}
+
+func (r exploreResult) WriteText(w io.Writer) error {
+	for _, p := range r.Pokemon {
+		methods := []string{}
+		for _, m := range p.Methods {
+			methods = append(methods, fmt.Sprintf("%s %d%%", m.Method, m.Chance))
+		}
+		fmt.Fprintf(w, "%s lv %s (%s)\n", p.Name, levelRange(p.MinLevel, p.MaxLevel), strings.Join(methods, ", "))
+	}
+	return nil
+}
+
+func (r exploreResult) Header() []string {
+	return []string{"pokemon", "min_level", "max_level", "method", "chance"}
+}
+
+func (r exploreResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, p := range r.Pokemon {
+		for _, m := range p.Methods {
+			rows = append(rows, []string{p.Name, strconv.Itoa(p.MinLevel), strconv.Itoa(p.MaxLevel), m.Method, strconv.Itoa(m.Chance)})
+		}
+	}
+	return rows
+}
+
+func levelRange(minLevel, maxLevel int) string {
+	if minLevel == maxLevel {
+		return strconv.Itoa(minLevel)
+	}
+	return fmt.Sprintf("%d-%d", minLevel, maxLevel)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
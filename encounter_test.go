@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+const routeArea = `{
+	"name": "route-29-area",
+	"pokemon_encounters": [
+		{
+			"pokemon": {"name": "pidgey"},
+			"version_details": [
+				{"version": {"name": "gold"}, "encounter_details": [
+					{"min_level": 2, "max_level": 3, "chance": 90, "method": {"name": "walk"}, "condition_values": [{"name": "time-morning"}]},
+					{"min_level": 2, "max_level": 2, "chance": 10, "method": {"name": "walk"}, "condition_values": [{"name": "time-day"}]}
+				]}
+			]
+		},
+		{
+			"pokemon": {"name": "hoothoot"},
+			"version_details": [
+				{"version": {"name": "gold"}, "encounter_details": [
+					{"min_level": 2, "max_level": 2, "chance": 90, "method": {"name": "walk"}, "condition_values": [{"name": "time-night"}]}
+				]}
+			]
+		},
+		{
+			"pokemon": {"name": "sentret"},
+			"version_details": [
+				{"version": {"name": "gold"}, "encounter_details": [
+					{"min_level": 2, "max_level": 3, "chance": 90, "method": {"name": "walk"}, "condition_values": [{"name": "time-day"}]},
+					{"min_level": 4, "max_level": 4, "chance": 50, "method": {"name": "walk"}, "condition_values": [{"name": "swarm-yes"}]}
+				]},
+				{"version": {"name": "silver"}, "encounter_details": [
+					{"min_level": 2, "max_level": 3, "chance": 100, "method": {"name": "walk"}, "condition_values": []}
+				]}
+			]
+		}
+	]
+}`
+
+func TestEncounterSlots(t *testing.T) {
+	var area pokeapi.PokemonEncounterList
+	if err := json.Unmarshal([]byte(routeArea), &area); err != nil {
+		t.Fatal(err)
+	}
+
+	slots := encounterSlots(area, "gold", "time-day")
+	if len(slots) != 2 {
+		t.Fatalf("expected 2 daytime slots, got %d", len(slots))
+	}
+	if slots[0].Pokemon != "pidgey" || slots[1].Pokemon != "sentret" {
+		t.Errorf("unexpected slots: %+v", slots)
+	}
+}
+
+func TestRollEncounterWeights(t *testing.T) {
+	slots := []encounterSlot{
+		{Pokemon: "pidgey", Encounter: pokeapi.Encounter{Chance: 90, MinLevel: 2, MaxLevel: 4}},
+		{Pokemon: "sentret", Encounter: pokeapi.Encounter{Chance: 10, MinLevel: 3, MaxLevel: 3}},
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		wild, ok := rollEncounter(rng, slots)
+		if !ok {
+			t.Fatal("expected an encounter")
+		}
+		if wild.Pokemon == "pidgey" && (wild.Level < 2 || wild.Level > 4) {
+			t.Errorf("level %d out of range", wild.Level)
+		}
+		counts[wild.Pokemon]++
+	}
+	if counts["sentret"] < 800 || counts["sentret"] > 1200 {
+		t.Errorf("expected about 1000 sentret, got %d", counts["sentret"])
+	}
+}
+
+func TestPickVersion(t *testing.T) {
+	var area pokeapi.PokemonEncounterList
+	if err := json.Unmarshal([]byte(routeArea), &area); err != nil {
+		t.Fatal(err)
+	}
+	if v := pickVersion(area, "silver"); v != "silver" {
+		t.Errorf("expected silver, got %s", v)
+	}
+	if v := pickVersion(area, "red"); v != "gold" {
+		t.Errorf("expected gold fallback, got %s", v)
+	}
+}
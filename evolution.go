@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+func commandEvolution(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) != 1 {
+		return nil, errors.New("you must provide a pokemon name")
+	}
+
+	pokemon, err := cfg.pokeapiClient.GetPokemonContext(ctx, params[0])
+	if err != nil {
+		return nil, err
+	}
+	chain, err := evolutionChainFor(ctx, cfg, pokemon)
+	if err != nil {
+		return nil, err
+	}
+	return newEvolutionResult(chain.Chain), nil
+}
+
+// evolveState is what we know about an owned Pokemon when checking whether
+// it meets an evolution's conditions.
+type evolveState struct {
+	Level     int
+	Item      string
+	Trade     bool
+	TimeOfDay string
+}
+
+func commandEvolve(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) < 1 || len(params) > 3 {
+		return nil, errors.New("usage: evolve <pokemon_name> [trade] [item]")
+	}
+
+	owned, _, err := cfg.collection.find(params[0])
+	if err != nil {
+		return nil, err
+	}
+	state := evolveState{
+		Level:     owned.Level,
+		TimeOfDay: timeOfDay(time.Now()),
+	}
+	if err := evolveArgs(&state, params[1:]); err != nil {
+		return nil, err
+	}
+
+	species, err := cfg.pokeapiClient.GetPokemonSpeciesContext(ctx, owned.Pokemon.Species.Name)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := cfg.pokeapiClient.GetEvolutionChainContext(ctx, species.EvolutionChainID())
+	if err != nil {
+		return nil, err
+	}
+	res := evolveResult{Pokemon: owned.Pokemon.Name, Missing: []evolutionRequirement{}}
+	link, ok := chain.Chain.Find(species.Name)
+	if !ok || len(link.EvolvesTo) == 0 {
+		return res, nil
+	}
+
+	for _, next := range link.EvolvesTo {
+		for _, d := range next.EvolutionDetails {
+			if len(unmetConditions(d, state)) > 0 {
+				continue
+			}
+			evolved, err := cfg.pokeapiClient.GetPokemonContext(ctx, next.Species.Name)
+			if err != nil {
+				return nil, err
+			}
+			owned.Pokemon = evolved
+			cfg.pokedex.catch(next.Species.Name, nationalNumber(next.Species.URL))
+			autosave(cfg)
+			res.EvolvedInto = evolved.Name
+			return res, nil
+		}
+	}
+
+	for _, next := range link.EvolvesTo {
+		for _, d := range next.EvolutionDetails {
+			res.Missing = append(res.Missing, evolutionRequirement{
+				Species: next.Species.Name,
+				Needs:   unmetConditions(d, state),
+			})
+		}
+	}
+	return res, nil
+}
+
+type evolutionStep struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	How  []string `json:"how"`
+}
+
+type evolutionResult struct {
+	Species    string          `json:"species"`
+	Evolutions []evolutionStep `json:"evolutions"`
+	chain      pokeapi.ChainLink
+}
+
+func newEvolutionResult(chain pokeapi.ChainLink) evolutionResult {
+	res := evolutionResult{
+		Species:    chain.Species.Name,
+		Evolutions: []evolutionStep{},
+		chain:      chain,
+	}
+	var walk func(link pokeapi.ChainLink)
+	walk = func(link pokeapi.ChainLink) {
+		for _, next := range link.EvolvesTo {
+			step := evolutionStep{From: link.Species.Name, To: next.Species.Name, How: []string{}}
+			for _, d := range next.EvolutionDetails {
+				step.How = append(step.How, describeEvolution(d))
+			}
+			res.Evolutions = append(res.Evolutions, step)
+			walk(next)
+		}
+	}
+	walk(chain)
+	return res
+}
+
+func (r evolutionResult) WriteText(w io.Writer) error {
+	_, err := io.WriteString(w, renderEvolutionTree(r.chain))
+	return err
+}
+
+func (r evolutionResult) Header() []string {
+	return []string{"from", "to", "how"}
+}
+
+func (r evolutionResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, e := range r.Evolutions {
+		rows = append(rows, []string{e.From, e.To, strings.Join(e.How, " or ")})
+	}
+	return rows
+}
+
+type evolutionRequirement struct {
+	Species string   `json:"species"`
+	Needs   []string `json:"needs"`
+}
+
+type evolveResult struct {
+	Pokemon     string                 `json:"pokemon"`
+	EvolvedInto string                 `json:"evolved_into,omitempty"`
+	Missing     []evolutionRequirement `json:"missing"`
+}
+
+func (r evolveResult) WriteText(w io.Writer) error {
+	switch {
+	case r.EvolvedInto != "":
+		fmt.Fprintf(w, "What? %s is evolving!\n", r.Pokemon)
+		fmt.Fprintf(w, "Congratulations! It evolved into %s!\n", r.EvolvedInto)
+	case len(r.Missing) == 0:
+		fmt.Fprintf(w, "%s does not evolve any further\n", r.Pokemon)
+	default:
+		fmt.Fprintf(w, "%s cannot evolve yet:\n", r.Pokemon)
+		for _, m := range r.Missing {
+			fmt.Fprintf(w, "  - %s needs %s\n", m.Species, strings.Join(m.Needs, ", "))
+		}
+	}
+	return nil
+}
+
+func (r evolveResult) Header() []string {
+	return []string{"pokemon", "evolved_into", "species", "needs"}
+}
+
+// Rows has one row per unmet evolution, or a single row when the Pokemon
+// evolved or has nothing to evolve into.
+func (r evolveResult) Rows() [][]string {
+	if len(r.Missing) == 0 {
+		return [][]string{{r.Pokemon, r.EvolvedInto, "", ""}}
+	}
+	rows := [][]string{}
+	for _, m := range r.Missing {
+		rows = append(rows, []string{r.Pokemon, "", m.Species, strings.Join(m.Needs, "; ")})
+	}
+	return rows
+}
+
+func evolutionChainFor(ctx context.Context, cfg *config, pokemon pokeapi.Pokemon) (pokeapi.EvolutionChain, error) {
+	species, err := cfg.pokeapiClient.GetPokemonSpeciesContext(ctx, pokemon.Species.Name)
+	if err != nil {
+		return pokeapi.EvolutionChain{}, err
+	}
+	return cfg.pokeapiClient.GetEvolutionChainContext(ctx, species.EvolutionChainID())
+}
+
+// evolveArgs reads the optional "trade" and item arguments of evolve into
+// state. Both can be given, in either order, for trades that need a held
+// item such as "evolve onix trade metal-coat".
+func evolveArgs(state *evolveState, args []string) error {
+	for _, arg := range args {
+		switch {
+		case arg == "trade" && !state.Trade:
+			state.Trade = true
+		case arg != "trade" && state.Item == "":
+			state.Item = arg
+		default:
+			return errors.New("usage: evolve <pokemon_name> [trade] [item]")
+		}
+	}
+	return nil
+}
+
+// unmetConditions lists the requirements of d that state does not satisfy.
+// Conditions we cannot track, such as happiness, gender, known moves or
+// party members, are always reported as unmet.
+func unmetConditions(d pokeapi.EvolutionDetail, state evolveState) []string {
+	unmet := []string{}
+	switch d.Trigger.Name {
+	case "level-up", "use-item":
+	case "trade":
+		if !state.Trade {
+			unmet = append(unmet, "a trade")
+		}
+	default:
+		unmet = append(unmet, "trigger "+d.Trigger.Name)
+	}
+
+	if d.MinLevel != nil && state.Level < *d.MinLevel {
+		unmet = append(unmet, fmt.Sprintf("level %d", *d.MinLevel))
+	}
+	if d.MinHappiness != nil {
+		unmet = append(unmet, fmt.Sprintf("happiness %d", *d.MinHappiness))
+	}
+	if d.Item != nil && state.Item != d.Item.Name {
+		unmet = append(unmet, "a "+d.Item.Name)
+	}
+	if d.HeldItem != nil && state.Item != d.HeldItem.Name {
+		unmet = append(unmet, "to hold a "+d.HeldItem.Name)
+	}
+	if d.TimeOfDay != "" && state.TimeOfDay != d.TimeOfDay {
+		unmet = append(unmet, "to be "+d.TimeOfDay+" time")
+	}
+	if d.KnownMove != nil {
+		unmet = append(unmet, "to know "+d.KnownMove.Name)
+	}
+	if d.KnownMoveType != nil {
+		unmet = append(unmet, "to know a "+d.KnownMoveType.Name+" move")
+	}
+	if d.Location != nil {
+		unmet = append(unmet, "to be at "+d.Location.Name)
+	}
+	if d.PartySpecies != nil {
+		unmet = append(unmet, d.PartySpecies.Name+" in the party")
+	}
+	if d.PartyType != nil {
+		unmet = append(unmet, "a "+d.PartyType.Name+" type in the party")
+	}
+	if d.TradeSpecies != nil {
+		unmet = append(unmet, "a trade for "+d.TradeSpecies.Name)
+	}
+	if d.MinBeauty != nil {
+		unmet = append(unmet, fmt.Sprintf("beauty %d", *d.MinBeauty))
+	}
+	if d.MinAffection != nil {
+		unmet = append(unmet, fmt.Sprintf("affection %d", *d.MinAffection))
+	}
+	if d.Gender != nil {
+		unmet = append(unmet, "to be "+genderName(*d.Gender))
+	}
+	if d.RelativePhysicalStats != nil {
+		unmet = append(unmet, physicalStatsName(*d.RelativePhysicalStats))
+	}
+	if d.NeedsOverworldRain {
+		unmet = append(unmet, "rain")
+	}
+	if d.TurnUpsideDown {
+		unmet = append(unmet, "to turn the console upside down")
+	}
+	return unmet
+}
+
+// genderName names PokeAPI's gender ids.
+func genderName(id int) string {
+	switch id {
+	case 1:
+		return "female"
+	case 2:
+		return "male"
+	}
+	return "genderless"
+}
+
+// physicalStatsName describes a relative_physical_stats requirement, which
+// compares attack to defense.
+func physicalStatsName(cmp int) string {
+	switch {
+	case cmp > 0:
+		return "attack above defense"
+	case cmp < 0:
+		return "attack below defense"
+	}
+	return "attack equal to defense"
+}
+
+func timeOfDay(t time.Time) string {
+	if h := t.Hour(); h >= 6 && h < 18 {
+		return "day"
+	}
+	return "night"
+}
+
+// describeEvolution summarises how an evolution is triggered, e.g.
+// "level 16" or "use thunder-stone".
+func describeEvolution(d pokeapi.EvolutionDetail) string {
+	parts := []string{}
+	switch d.Trigger.Name {
+	case "level-up":
+		if d.MinLevel != nil {
+			parts = append(parts, fmt.Sprintf("level %d", *d.MinLevel))
+		} else {
+			parts = append(parts, "level up")
+		}
+	case "use-item":
+		if d.Item != nil {
+			parts = append(parts, "use "+d.Item.Name)
+		}
+	case "trade":
+		parts = append(parts, "trade")
+	default:
+		parts = append(parts, d.Trigger.Name)
+	}
+
+	if d.HeldItem != nil {
+		parts = append(parts, "holding "+d.HeldItem.Name)
+	}
+	if d.MinHappiness != nil {
+		parts = append(parts, fmt.Sprintf("happiness %d+", *d.MinHappiness))
+	}
+	if d.MinAffection != nil {
+		parts = append(parts, fmt.Sprintf("affection %d+", *d.MinAffection))
+	}
+	if d.KnownMove != nil {
+		parts = append(parts, "knowing "+d.KnownMove.Name)
+	}
+	if d.KnownMoveType != nil {
+		parts = append(parts, "knowing a "+d.KnownMoveType.Name+" move")
+	}
+	if d.Location != nil {
+		parts = append(parts, "at "+d.Location.Name)
+	}
+	if d.TimeOfDay != "" {
+		parts = append(parts, "during "+d.TimeOfDay)
+	}
+	if d.TradeSpecies != nil {
+		parts = append(parts, "for "+d.TradeSpecies.Name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func renderEvolutionTree(root pokeapi.ChainLink) string {
+	var b strings.Builder
+	b.WriteString(root.Species.Name + "\n")
+	renderEvolutionChildren(&b, root, "")
+	return b.String()
+}
+
+func renderEvolutionChildren(b *strings.Builder, link pokeapi.ChainLink, prefix string) {
+	for i, next := range link.EvolvesTo {
+		branch, indent := "├── ", "│   "
+		if i == len(link.EvolvesTo)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		how := []string{}
+		for _, d := range next.EvolutionDetails {
+			how = append(how, describeEvolution(d))
+		}
+		b.WriteString(prefix + branch + next.Species.Name)
+		if len(how) > 0 {
+			b.WriteString(" (" + strings.Join(how, " or ") + ")")
+		}
+		b.WriteString("\n")
+		renderEvolutionChildren(b, next, prefix+indent)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+const eeveeChain = `{
+	"species": {"name": "eevee"},
+	"evolves_to": [
+		{
+			"species": {"name": "vaporeon"},
+			"evolution_details": [{"trigger": {"name": "use-item"}, "item": {"name": "water-stone"}}],
+			"evolves_to": []
+		},
+		{
+			"species": {"name": "espeon"},
+			"evolution_details": [{"trigger": {"name": "level-up"}, "min_happiness": 160, "time_of_day": "day"}],
+			"evolves_to": []
+		}
+	]
+}`
+
+func TestRenderEvolutionTree(t *testing.T) {
+	var chain pokeapi.ChainLink
+	if err := json.Unmarshal([]byte(eeveeChain), &chain); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "eevee\n" +
+		"├── vaporeon (use water-stone)\n" +
+		"└── espeon (level up, happiness 160+, during day)\n"
+	if actual := renderEvolutionTree(chain); actual != expected {
+		t.Errorf("Result:\n%s\ndoes not equal expected:\n%s", actual, expected)
+	}
+}
+
+func TestUnmetConditions(t *testing.T) {
+	level := 16
+	levelUp := pokeapi.EvolutionDetail{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, MinLevel: &level}
+	trade := pokeapi.EvolutionDetail{Trigger: pokeapi.NamedAPIResource{Name: "trade"}}
+	female, above := 1, 1
+	gender := pokeapi.EvolutionDetail{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, MinLevel: &level, Gender: &female}
+	stats := pokeapi.EvolutionDetail{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, MinLevel: &level, RelativePhysicalStats: &above}
+	happiness := 220
+	friendship := pokeapi.EvolutionDetail{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, MinHappiness: &happiness}
+
+	cases := []struct {
+		detail pokeapi.EvolutionDetail
+		state  evolveState
+		unmet  int
+	}{
+		{detail: levelUp, state: evolveState{Level: 15}, unmet: 1},
+		{detail: levelUp, state: evolveState{Level: 16}, unmet: 0},
+		{detail: trade, state: evolveState{}, unmet: 1},
+		{detail: trade, state: evolveState{Trade: true}, unmet: 0},
+		{detail: gender, state: evolveState{Level: 20}, unmet: 1},
+		{detail: stats, state: evolveState{Level: 20}, unmet: 1},
+		{detail: friendship, state: evolveState{Level: 20}, unmet: 1},
+	}
+
+	for _, c := range cases {
+		if unmet := unmetConditions(c.detail, c.state); len(unmet) != c.unmet {
+			t.Errorf("expected %d unmet conditions, got %v", c.unmet, unmet)
+		}
+	}
+}
+
+func TestTradeWithHeldItem(t *testing.T) {
+	steelix := pokeapi.EvolutionDetail{
+		Trigger:  pokeapi.NamedAPIResource{Name: "trade"},
+		HeldItem: &pokeapi.NamedAPIResource{Name: "metal-coat"},
+	}
+
+	cases := []struct {
+		args  []string
+		unmet int
+	}{
+		{args: []string{"trade"}, unmet: 1},
+		{args: []string{"metal-coat"}, unmet: 1},
+		{args: []string{"trade", "metal-coat"}, unmet: 0},
+		{args: []string{"metal-coat", "trade"}, unmet: 0},
+	}
+
+	for _, c := range cases {
+		state := evolveState{Level: 30}
+		if err := evolveArgs(&state, c.args); err != nil {
+			t.Errorf("unexpected error for %v: %v", c.args, err)
+			continue
+		}
+		if unmet := unmetConditions(steelix, state); len(unmet) != c.unmet {
+			t.Errorf("expected %d unmet conditions for %v, got %v", c.unmet, c.args, unmet)
+		}
+	}
+
+	for _, args := range [][]string{{"trade", "trade"}, {"metal-coat", "king-s-rock"}} {
+		if err := evolveArgs(&evolveState{}, args); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package battle
+
+import "errors"
+
+// RNG is the source of randomness for a battle. *math/rand.Rand satisfies
+// it, so battles seeded with the same value play out identically.
+type RNG interface {
+	Intn(n int) int
+}
+
+// Effectiveness returns the type multiplier of an attacking type against
+// the defending types.
+type Effectiveness func(attacking string, defending []string) float64
+
+type Stats struct {
+	HP             int `json:"hp"`
+	Attack         int `json:"attack"`
+	Defense        int `json:"defense"`
+	SpecialAttack  int `json:"special_attack"`
+	SpecialDefense int `json:"special_defense"`
+	Speed          int `json:"speed"`
+}
+
+const (
+	Physical = "physical"
+	Special  = "special"
+	Status   = "status"
+)
+
+type Move struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	DamageClass string `json:"damage_class"`
+	Power       int    `json:"power"`
+	// Accuracy is a percentage; 0 means the move never misses.
+	Accuracy int `json:"accuracy"`
+	PP       int `json:"pp"`
+	Priority int `json:"priority"`
+}
+
+// Struggle is used once a combatant has no PP left in any move.
+var Struggle = Move{Name: "struggle", DamageClass: Physical, Power: 50}
+
+type MoveSlot struct {
+	Move Move `json:"move"`
+	PP   int  `json:"pp"`
+}
+
+type Combatant struct {
+	Name  string     `json:"name"`
+	Level int        `json:"level"`
+	Types []string   `json:"types"`
+	Stats Stats      `json:"stats"`
+	HP    int        `json:"hp"`
+	Moves []MoveSlot `json:"moves"`
+}
+
+func NewCombatant(name string, level int, types []string, stats Stats, moves []Move) *Combatant {
+	c := &Combatant{
+		Name:  name,
+		Level: level,
+		Types: types,
+		Stats: stats,
+		HP:    stats.HP,
+		Moves: []MoveSlot{},
+	}
+	for _, m := range moves {
+		c.Moves = append(c.Moves, MoveSlot{Move: m, PP: m.PP})
+	}
+	return c
+}
+
+func (c *Combatant) Fainted() bool {
+	return c.HP <= 0
+}
+
+func (c *Combatant) hasType(t string) bool {
+	for _, own := range c.Types {
+		if own == t {
+			return true
+		}
+	}
+	return false
+}
+
+// usableMoves returns the indexes of moves with PP left.
+func (c *Combatant) usableMoves() []int {
+	usable := []int{}
+	for i, m := range c.Moves {
+		if m.PP > 0 {
+			usable = append(usable, i)
+		}
+	}
+	return usable
+}
+
+// Event describes one attack during a turn.
+type Event struct {
+	Attacker      string  `json:"attacker"`
+	Defender      string  `json:"defender"`
+	Move          string  `json:"move"`
+	Missed        bool    `json:"missed"`
+	Damage        int     `json:"damage"`
+	Critical      bool    `json:"critical"`
+	Effectiveness float64 `json:"effectiveness"`
+	Fainted       bool    `json:"fainted"`
+}
+
+type Battle struct {
+	Player        *Combatant
+	Opponent      *Combatant
+	Turns         int
+	rng           RNG
+	effectiveness Effectiveness
+}
+
+func New(player, opponent *Combatant, rng RNG, effectiveness Effectiveness) *Battle {
+	return &Battle{
+		Player:        player,
+		Opponent:      opponent,
+		rng:           rng,
+		effectiveness: effectiveness,
+	}
+}
+
+func (b *Battle) Over() bool {
+	return b.Player.Fainted() || b.Opponent.Fainted()
+}
+
+var (
+	ErrBattleOver = errors.New("the battle is over")
+	ErrNoPP       = errors.New("that move has no PP left")
+	ErrBadMove    = errors.New("no such move")
+)
+
+// Turn plays one round: the player uses the move at index playerMove, the
+// opponent picks a random move with PP left, and the faster side attacks
+// first. Passing -1 makes the player struggle, which is only allowed once
+// every move is out of PP.
+func (b *Battle) Turn(playerMove int) ([]Event, error) {
+	if b.Over() {
+		return nil, ErrBattleOver
+	}
+	pMove, err := b.pick(b.Player, playerMove)
+	if err != nil {
+		return nil, err
+	}
+	oMove, _ := b.pick(b.Opponent, b.randomMove(b.Opponent))
+
+	b.Turns++
+	first, second := b.Player, b.Opponent
+	firstMove, secondMove := pMove, oMove
+	if b.opponentFirst(pMove, oMove) {
+		first, second = second, first
+		firstMove, secondMove = secondMove, firstMove
+	}
+
+	events := []Event{b.attack(first, second, firstMove)}
+	if !second.Fainted() {
+		events = append(events, b.attack(second, first, secondMove))
+	}
+	return events, nil
+}
+
+func (b *Battle) pick(c *Combatant, index int) (Move, error) {
+	if index == -1 {
+		if len(c.usableMoves()) > 0 {
+			return Move{}, ErrBadMove
+		}
+		return Struggle, nil
+	}
+	if index < 0 || index >= len(c.Moves) {
+		return Move{}, ErrBadMove
+	}
+	if c.Moves[index].PP <= 0 {
+		return Move{}, ErrNoPP
+	}
+	c.Moves[index].PP--
+	return c.Moves[index].Move, nil
+}
+
+func (b *Battle) randomMove(c *Combatant) int {
+	usable := c.usableMoves()
+	if len(usable) == 0 {
+		return -1
+	}
+	return usable[b.rng.Intn(len(usable))]
+}
+
+func (b *Battle) opponentFirst(pMove, oMove Move) bool {
+	if pMove.Priority != oMove.Priority {
+		return oMove.Priority > pMove.Priority
+	}
+	if b.Player.Stats.Speed != b.Opponent.Stats.Speed {
+		return b.Opponent.Stats.Speed > b.Player.Stats.Speed
+	}
+	return b.rng.Intn(2) == 0
+}
+
+func (b *Battle) attack(attacker, defender *Combatant, move Move) Event {
+	e := Event{Attacker: attacker.Name, Defender: defender.Name, Move: move.Name, Effectiveness: 1}
+	if move.Accuracy > 0 && b.rng.Intn(100) >= move.Accuracy {
+		e.Missed = true
+		return e
+	}
+
+	e.Damage, e.Critical, e.Effectiveness = Damage(b.rng, attacker, defender, move, b.effectiveness)
+	defender.HP = max(defender.HP-e.Damage, 0)
+	e.Fainted = defender.Fainted()
+	return e
+}
+
+// CriticalChance is the chance of a critical hit, 1 in 24 as in
+// generation VII onwards.
+const CriticalChance = 24
+
+// Damage computes the damage of move using the standard formula:
+//
+//	((2*Level/5 + 2) * Power * A/D / 50 + 2) * random * STAB * type * critical
+//
+// where random is between 0.85 and 1, STAB is 1.5 when the move shares a
+// type with the attacker, and critical hits deal 1.5 times the damage.
+// Status moves and immune defenders take no damage.
+func Damage(rng RNG, attacker, defender *Combatant, move Move, effectiveness Effectiveness) (int, bool, float64) {
+	if move.DamageClass == Status || move.Power <= 0 {
+		return 0, false, 1
+	}
+
+	multiplier := 1.0
+	if move.Type != "" && effectiveness != nil {
+		multiplier = effectiveness(move.Type, defender.Types)
+	}
+	if multiplier == 0 {
+		return 0, false, 0
+	}
+
+	a, d := attacker.Stats.Attack, defender.Stats.Defense
+	if move.DamageClass == Special {
+		a, d = attacker.Stats.SpecialAttack, defender.Stats.SpecialDefense
+	}
+	a, d = max(a, 1), max(d, 1)
+
+	base := (2*attacker.Level/5+2)*move.Power*a/d/50 + 2
+	critical := rng.Intn(CriticalChance) == 0
+
+	damage := float64(base) * float64(85+rng.Intn(16)) / 100
+	if move.Type != "" && attacker.hasType(move.Type) {
+		damage *= 1.5
+	}
+	damage *= multiplier
+	if critical {
+		damage *= 1.5
+	}
+	return max(int(damage), 1), critical, multiplier
+}
@@ -0,0 +1,108 @@
+package battle
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// fixedRNG always returns the same roll, capped to the requested range.
+type fixedRNG struct {
+	val int
+}
+
+func (f fixedRNG) Intn(n int) int {
+	return min(f.val, n-1)
+}
+
+func neutral(attacking string, defending []string) float64 {
+	return 1
+}
+
+var (
+	tackle      = Move{Name: "tackle", Type: "normal", DamageClass: Physical, Power: 40, Accuracy: 100, PP: 35}
+	thunderbolt = Move{Name: "thunderbolt", Type: "electric", DamageClass: Special, Power: 90, Accuracy: 100, PP: 15}
+	quickAttack = Move{Name: "quick-attack", Type: "normal", DamageClass: Physical, Power: 40, Accuracy: 100, PP: 30, Priority: 1}
+)
+
+func pikachu() *Combatant {
+	return NewCombatant("pikachu", 20, []string{"electric"},
+		Stats{HP: 50, Attack: 30, Defense: 20, SpecialAttack: 28, SpecialDefense: 24, Speed: 43},
+		[]Move{thunderbolt, quickAttack})
+}
+
+func pidgey() *Combatant {
+	return NewCombatant("pidgey", 20, []string{"normal", "flying"},
+		Stats{HP: 48, Attack: 24, Defense: 22, SpecialAttack: 20, SpecialDefense: 20, Speed: 30},
+		[]Move{tackle})
+}
+
+func TestDamage(t *testing.T) {
+	// Max roll (index 15 of 16) and no critical hit (roll 15 of 24).
+	rng := fixedRNG{15}
+	damage, crit, multiplier := Damage(rng, pikachu(), pidgey(), thunderbolt, func(string, []string) float64 { return 2 })
+	// base = (2*20/5+2) * 90 * 28/20 / 50 + 2 = 10*90*28/20/50 + 2 = 27
+	// 27 * 1.00 * 1.5 (STAB) * 2 = 81
+	if damage != 81 || crit || multiplier != 2 {
+		t.Errorf("expected 81 damage without a crit, got %d crit=%v x%v", damage, crit, multiplier)
+	}
+
+	damage, crit, _ = Damage(fixedRNG{0}, pikachu(), pidgey(), thunderbolt, neutral)
+	// 27 * 0.85 * 1.5 * 1.5 (crit) = 51.6
+	if damage != 51 || !crit {
+		t.Errorf("expected a 51 damage crit, got %d crit=%v", damage, crit)
+	}
+
+	if damage, _, _ := Damage(rng, pikachu(), pidgey(), thunderbolt, func(string, []string) float64 { return 0 }); damage != 0 {
+		t.Errorf("expected no damage against an immune defender, got %d", damage)
+	}
+}
+
+func TestTurnOrder(t *testing.T) {
+	player := pidgey()
+	opponent := pikachu()
+	opponent.Moves = []MoveSlot{{Move: tackle, PP: 1}}
+	player.Moves = []MoveSlot{{Move: quickAttack, PP: 1}}
+
+	b := New(player, opponent, fixedRNG{15}, neutral)
+	events, err := b.Turn(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if events[0].Attacker != "pidgey" {
+		t.Errorf("expected the priority move to go first, got %s", events[0].Attacker)
+	}
+	if player.Moves[0].PP != 0 {
+		t.Errorf("expected PP to be used")
+	}
+	if _, err := b.Turn(0); err != ErrNoPP {
+		t.Errorf("expected ErrNoPP, got %v", err)
+	}
+}
+
+func TestBattleIsDeterministic(t *testing.T) {
+	play := func(seed int64) []Event {
+		b := New(pikachu(), pidgey(), rand.New(rand.NewSource(seed)), neutral)
+		all := []Event{}
+		for !b.Over() {
+			events, err := b.Turn(0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			all = append(all, events...)
+		}
+		return all
+	}
+
+	first, second := play(42), play(42)
+	if len(first) != len(second) {
+		t.Fatalf("expected identical battles, got %d and %d events", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("event %d differs: %+v vs %+v", i, first[i], second[i])
+		}
+	}
+	if !first[len(first)-1].Fainted {
+		t.Errorf("expected the battle to end with a faint")
+	}
+}
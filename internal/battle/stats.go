@@ -0,0 +1,105 @@
+package battle
+
+const (
+	MaxLevel    = 100
+	MaxIV       = 31
+	MaxEV       = 252
+	MaxTotalEVs = 510
+)
+
+// StatNames are PokeAPI's names for the stats, in the order of the fields
+// of Stats.
+var StatNames = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+// Get returns the stat with the given PokeAPI name, or 0 for unknown names.
+func (s Stats) Get(name string) int {
+	if p := s.field(name); p != nil {
+		return *p
+	}
+	return 0
+}
+
+func (s *Stats) Set(name string, v int) {
+	if p := s.field(name); p != nil {
+		*p = v
+	}
+}
+
+func (s *Stats) field(name string) *int {
+	switch name {
+	case "hp":
+		return &s.HP
+	case "attack":
+		return &s.Attack
+	case "defense":
+		return &s.Defense
+	case "special-attack":
+		return &s.SpecialAttack
+	case "special-defense":
+		return &s.SpecialDefense
+	case "speed":
+		return &s.Speed
+	}
+	return nil
+}
+
+func (s Stats) total() int {
+	return s.HP + s.Attack + s.Defense + s.SpecialAttack + s.SpecialDefense + s.Speed
+}
+
+// Nature raises one stat by 10% and lowers another by 10%. Neutral natures
+// leave both empty.
+type Nature struct {
+	Name      string `json:"name"`
+	Increased string `json:"increased,omitempty"`
+	Decreased string `json:"decreased,omitempty"`
+}
+
+// Calculate computes stats at level the way generation III onwards does:
+//
+//	HP    = (2*Base + IV + EV/4) * Level/100 + Level + 10
+//	Other = ((2*Base + IV + EV/4) * Level/100 + 5) * nature
+func Calculate(base, ivs, evs Stats, level int, nature Nature) Stats {
+	stats := Stats{}
+	for _, name := range StatNames {
+		v := (2*base.Get(name) + ivs.Get(name) + evs.Get(name)/4) * level / 100
+		if name == "hp" {
+			stats.Set(name, v+level+10)
+			continue
+		}
+		v += 5
+		switch name {
+		case nature.Increased:
+			v = v * 110 / 100
+		case nature.Decreased:
+			v = v * 90 / 100
+		}
+		stats.Set(name, v)
+	}
+	return stats
+}
+
+// RandomIVs rolls each individual value between 0 and MaxIV.
+func RandomIVs(rng RNG) Stats {
+	ivs := Stats{}
+	for _, name := range StatNames {
+		ivs.Set(name, rng.Intn(MaxIV+1))
+	}
+	return ivs
+}
+
+// AddEVs adds gained effort values to evs, keeping each stat under MaxEV
+// and the total under MaxTotalEVs.
+func AddEVs(evs, gained Stats) Stats {
+	for _, name := range StatNames {
+		room := min(MaxEV-evs.Get(name), MaxTotalEVs-evs.total())
+		evs.Set(name, evs.Get(name)+max(min(gained.Get(name), room), 0))
+	}
+	return evs
+}
+
+// ExperienceYield is the experience for defeating a wild Pokemon with the
+// given base experience and level, as in generations I to IV.
+func ExperienceYield(baseExperience, level int) int {
+	return max(baseExperience*level/7, 1)
+}
@@ -0,0 +1,47 @@
+package battle
+
+import "testing"
+
+func TestCalculate(t *testing.T) {
+	// Garchomp from Bulbapedia's stat example: level 78, Adamant.
+	base := Stats{HP: 108, Attack: 130, Defense: 95, SpecialAttack: 80, SpecialDefense: 85, Speed: 102}
+	ivs := Stats{HP: 24, Attack: 12, Defense: 30, SpecialAttack: 16, SpecialDefense: 23, Speed: 5}
+	evs := Stats{HP: 74, Attack: 190, Defense: 91, SpecialAttack: 48, SpecialDefense: 84, Speed: 23}
+	adamant := Nature{Name: "adamant", Increased: "attack", Decreased: "special-attack"}
+
+	got := Calculate(base, ivs, evs, 78, adamant)
+	expected := Stats{HP: 289, Attack: 278, Defense: 193, SpecialAttack: 135, SpecialDefense: 171, Speed: 171}
+	if got != expected {
+		t.Errorf("Result: %+v, does not equal expected: %+v", got, expected)
+	}
+}
+
+func TestAddEVs(t *testing.T) {
+	cases := []struct {
+		evs      Stats
+		gained   Stats
+		expected Stats
+	}{
+		{
+			evs:      Stats{},
+			gained:   Stats{Speed: 2},
+			expected: Stats{Speed: 2},
+		},
+		{
+			evs:      Stats{Attack: 251},
+			gained:   Stats{Attack: 3},
+			expected: Stats{Attack: 252},
+		},
+		{
+			evs:      Stats{Attack: 252, Speed: 252, HP: 5},
+			gained:   Stats{HP: 3},
+			expected: Stats{Attack: 252, Speed: 252, HP: 6},
+		},
+	}
+
+	for _, c := range cases {
+		if got := AddEVs(c.evs, c.gained); got != c.expected {
+			t.Errorf("Result: %+v, does not equal expected: %+v", got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package capture
+
+import "math"
+
+// RNG is the source of randomness used by Attempt. *math/rand.Rand
+// satisfies it, and tests can supply a deterministic implementation.
+type RNG interface {
+	Intn(n int) int
+}
+
+type Ball struct {
+	Name     string
+	Modifier float64
+	// Guaranteed balls always succeed, like the Master Ball.
+	Guaranteed bool
+}
+
+var (
+	PokeBall   = Ball{Name: "poke-ball", Modifier: 1}
+	GreatBall  = Ball{Name: "great-ball", Modifier: 1.5}
+	UltraBall  = Ball{Name: "ultra-ball", Modifier: 2}
+	MasterBall = Ball{Name: "master-ball", Modifier: 255, Guaranteed: true}
+)
+
+// Balls indexes the supported balls by their PokeAPI item name.
+var Balls = map[string]Ball{
+	PokeBall.Name:   PokeBall,
+	GreatBall.Name:  GreatBall,
+	UltraBall.Name:  UltraBall,
+	MasterBall.Name: MasterBall,
+}
+
+type Status int
+
+const (
+	StatusNone Status = iota
+	StatusSleep
+	StatusFreeze
+	StatusParalysis
+	StatusBurn
+	StatusPoison
+)
+
+func (s Status) modifier() float64 {
+	switch s {
+	case StatusSleep, StatusFreeze:
+		return 2
+	case StatusParalysis, StatusBurn, StatusPoison:
+		return 1.5
+	default:
+		return 1
+	}
+}
+
+type Target struct {
+	// CaptureRate is the species capture_rate, from 3 (hardest) to 255.
+	CaptureRate int
+	MaxHP       int
+	CurrentHP   int
+	Status      Status
+}
+
+type Result struct {
+	Caught bool
+	// Shakes is how many times the ball shook before the outcome, 0 to 4.
+	Shakes int
+}
+
+const shakeChecks = 4
+
+// CatchValue returns the modified catch rate "a" from the generation III/IV
+// formula. Values of 255 or more always succeed.
+func CatchValue(t Target, ball Ball) float64 {
+	maxHP := float64(max(t.MaxHP, 1))
+	hp := float64(min(max(t.CurrentHP, 1), t.MaxHP))
+	return (3*maxHP - 2*hp) * float64(t.CaptureRate) * ball.Modifier / (3 * maxHP) * t.Status.modifier()
+}
+
+// ShakeProbability returns the chance that a single shake check passes.
+func ShakeProbability(t Target, ball Ball) float64 {
+	a := CatchValue(t, ball)
+	if ball.Guaranteed || a >= 255 {
+		return 1
+	}
+	if a <= 0 {
+		return 0
+	}
+	b := 1048560 / math.Sqrt(math.Sqrt(16711680/a))
+	return b / 65536
+}
+
+// Attempt throws ball at t and performs the four shake checks, each passing
+// when a random number in [0, 65535] is below the shake threshold.
+func Attempt(rng RNG, t Target, ball Ball) Result {
+	a := CatchValue(t, ball)
+	if ball.Guaranteed || a >= 255 {
+		return Result{Caught: true, Shakes: shakeChecks}
+	}
+	if a <= 0 {
+		return Result{}
+	}
+
+	b := int(1048560 / math.Sqrt(math.Sqrt(16711680/a)))
+	for shakes := 0; shakes < shakeChecks; shakes++ {
+		if rng.Intn(65536) >= b {
+			return Result{Shakes: shakes}
+		}
+	}
+	return Result{Caught: true, Shakes: shakeChecks}
+}
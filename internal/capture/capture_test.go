@@ -0,0 +1,104 @@
+package capture
+
+import (
+	"math/rand"
+	"testing"
+)
+
+type fixedRNG struct {
+	val int
+}
+
+func (f fixedRNG) Intn(n int) int {
+	return f.val % n
+}
+
+func TestAttempt(t *testing.T) {
+	cases := []struct {
+		name   string
+		rng    RNG
+		target Target
+		ball   Ball
+		caught bool
+		shakes int
+	}{
+		{
+			name:   "master ball always catches",
+			rng:    fixedRNG{65535},
+			target: Target{CaptureRate: 3, MaxHP: 100, CurrentHP: 100},
+			ball:   MasterBall,
+			caught: true,
+			shakes: 4,
+		},
+		{
+			name:   "high capture rate at low hp is guaranteed",
+			rng:    fixedRNG{65535},
+			target: Target{CaptureRate: 255, MaxHP: 100, CurrentHP: 1, Status: StatusSleep},
+			ball:   PokeBall,
+			caught: true,
+			shakes: 4,
+		},
+		{
+			name:   "lucky rolls catch",
+			rng:    fixedRNG{0},
+			target: Target{CaptureRate: 45, MaxHP: 100, CurrentHP: 100},
+			ball:   PokeBall,
+			caught: true,
+			shakes: 4,
+		},
+		{
+			name:   "unlucky rolls escape immediately",
+			rng:    fixedRNG{65535},
+			target: Target{CaptureRate: 45, MaxHP: 100, CurrentHP: 100},
+			ball:   PokeBall,
+			caught: false,
+			shakes: 0,
+		},
+		{
+			name:   "zero capture rate never catches",
+			rng:    fixedRNG{0},
+			target: Target{CaptureRate: 0, MaxHP: 100, CurrentHP: 100},
+			ball:   UltraBall,
+			caught: false,
+			shakes: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res := Attempt(c.rng, c.target, c.ball)
+			if res.Caught != c.caught || res.Shakes != c.shakes {
+				t.Errorf("expected caught=%v shakes=%d, got %+v", c.caught, c.shakes, res)
+			}
+		})
+	}
+}
+
+func TestBetterConditionsImproveOdds(t *testing.T) {
+	base := Target{CaptureRate: 45, MaxHP: 100, CurrentHP: 100}
+	weakened := Target{CaptureRate: 45, MaxHP: 100, CurrentHP: 10, Status: StatusParalysis}
+
+	if ShakeProbability(base, GreatBall) <= ShakeProbability(base, PokeBall) {
+		t.Errorf("expected great ball to beat poke ball")
+	}
+	if ShakeProbability(weakened, PokeBall) <= ShakeProbability(base, PokeBall) {
+		t.Errorf("expected a weakened target to be easier to catch")
+	}
+}
+
+func TestAttemptRate(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	target := Target{CaptureRate: 190, MaxHP: 35, CurrentHP: 35}
+	caught := 0
+	const throws = 10000
+	for i := 0; i < throws; i++ {
+		if Attempt(rng, target, PokeBall).Caught {
+			caught++
+		}
+	}
+	p := ShakeProbability(target, PokeBall)
+	expected := p * p * p * p * throws
+	if diff := float64(caught) - expected; diff > 300 || diff < -300 {
+		t.Errorf("expected about %.0f catches, got %d", expected, caught)
+	}
+}
@@ -2,21 +2,231 @@ package pokeapi
 
 import (
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rasmussecher/pokedex/internal/pokecache"
 )
 
+const (
+	DefaultBaseURL       = "https://pokeapi.co/api/v2"
+	DefaultTimeout       = 5 * time.Second
+	DefaultCacheInterval = 5 * time.Minute
+	// DefaultStaleRetention is how long the cache a client creates keeps
+	// expired responses so they can be revalidated.
+	DefaultStaleRetention = time.Hour
+)
+
 type Client struct {
 	cache      pokecache.Cache
+	flights    *flightGroup
+	metrics    *metrics
+	refreshes  *sync.WaitGroup
+	limiter    *limiter
+	retry      retryPolicy
 	httpClient http.Client
+	baseURL    string
+	userAgent  string
+	// staleWhileRevalidate is how long after expiring a response may still
+	// be served while it is refreshed in the background.
+	staleWhileRevalidate time.Duration
+}
+
+type clientOptions struct {
+	baseURL              string
+	userAgent            string
+	timeout              time.Duration
+	transport            http.RoundTripper
+	cache                *pokecache.Cache
+	cacheInterval        time.Duration
+	cacheOptions         []pokecache.Option
+	staleWhileRevalidate time.Duration
+	retry                retryPolicy
+	rateLimit            float64
+	rateBurst            int
+}
+
+// Option configures a Client created by NewClient.
+type Option func(*clientOptions)
+
+// WithBaseURL points the client at a PokeAPI instance other than the public
+// one, e.g. a self-hosted mirror or a test server.
+func WithBaseURL(baseURL string) Option {
+	return func(o *clientOptions) {
+		o.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.transport = rt
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithCache makes the client use an existing cache instead of creating one.
+func WithCache(cache pokecache.Cache) Option {
+	return func(o *clientOptions) {
+		o.cache = &cache
+	}
+}
+
+// WithCacheInterval sets the reap interval of the cache the client creates.
+// It has no effect when combined with WithCache.
+func WithCacheInterval(interval time.Duration) Option {
+	return func(o *clientOptions) {
+		o.cacheInterval = interval
+	}
+}
+
+// WithCacheOptions configures the cache the client creates, e.g. with
+// pokecache.WithMaxBytes. It has no effect when combined with WithCache.
+func WithCacheOptions(opts ...pokecache.Option) Option {
+	return func(o *clientOptions) {
+		o.cacheOptions = append(o.cacheOptions, opts...)
+	}
+}
+
+// WithStaleWhileRevalidate lets the client answer with a response that
+// expired up to window ago and refresh it in the background, instead of
+// waiting for the refresh. The cache must retain expired responses for at
+// least as long; a cache the client creates does.
+func WithStaleWhileRevalidate(window time.Duration) Option {
+	return func(o *clientOptions) {
+		o.staleWhileRevalidate = window
+	}
 }
 
-func NewClient(timeout, cacheInterval time.Duration) Client {
+// WithRetries sets how many times a GET that failed with a server error, a
+// 429 or a transport error such as a timeout is sent again. 0 disables
+// retries.
+func WithRetries(n int) Option {
+	return func(o *clientOptions) {
+		o.retry.retries = n
+	}
+}
+
+// WithRetryBackoff sets the delays between retries. Each retry waits a
+// random time up to backoff, doubled for every earlier attempt and capped
+// at maxBackoff, unless a 429 response said how long to wait.
+func WithRetryBackoff(backoff, maxBackoff time.Duration) Option {
+	return func(o *clientOptions) {
+		o.retry.backoff = backoff
+		o.retry.maxBackoff = maxBackoff
+	}
+}
+
+// WithRateLimit limits the client to perSecond requests per second on
+// average, allowing bursts of up to burst requests. Retries count against
+// the limit; cached and coalesced fetches do not. 0 means no limit.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(o *clientOptions) {
+		o.rateLimit = perSecond
+		o.rateBurst = burst
+	}
+}
+
+func NewClient(opts ...Option) Client {
+	o := clientOptions{
+		baseURL:       DefaultBaseURL,
+		timeout:       DefaultTimeout,
+		cacheInterval: DefaultCacheInterval,
+		retry: retryPolicy{
+			retries:    DefaultRetries,
+			backoff:    DefaultRetryBackoff,
+			maxBackoff: DefaultMaxRetryBackoff,
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cache := o.cache
+	if cache == nil {
+		retention := pokecache.WithStaleRetention(max(DefaultStaleRetention, o.staleWhileRevalidate))
+		c := pokecache.NewCache(o.cacheInterval, append([]pokecache.Option{retention}, o.cacheOptions...)...)
+		cache = &c
+	}
+
 	return Client{
-		cache: pokecache.NewCache(cacheInterval),
+		cache:     *cache,
+		flights:   newFlightGroup(),
+		metrics:   &metrics{},
+		refreshes: &sync.WaitGroup{},
+		limiter:   newLimiter(o.rateLimit, o.rateBurst),
+		retry:     o.retry,
 		httpClient: http.Client{
-			Timeout: timeout,
+			Timeout:   o.timeout,
+			Transport: o.transport,
 		},
+		baseURL:              o.baseURL,
+		userAgent:            o.userAgent,
+		staleWhileRevalidate: o.staleWhileRevalidate,
+	}
+}
+
+// Endpoint joins path segments onto the configured base URL.
+func (c *Client) Endpoint(parts ...string) string {
+	return c.baseURL + "/" + strings.Join(parts, "/")
+}
+
+// Close waits for background refreshes to finish and stops the background
+// work of the client's cache, including a cache passed in with WithCache.
+// The client must not be used afterwards.
+func (c *Client) Close() error {
+	c.refreshes.Wait()
+	return c.cache.Close()
+}
+
+// Cache returns the client's response cache.
+func (c *Client) Cache() *pokecache.Cache {
+	return &c.cache
+}
+
+// Stats counts a client's traffic to PokeAPI.
+type Stats struct {
+	// Requests is the number of HTTP requests sent.
+	Requests int64 `json:"requests"`
+	// Coalesced is the number of fetches answered by a request another
+	// caller already had in flight.
+	Coalesced int64 `json:"coalesced"`
+	// NotModified is the number of expired responses that PokeAPI
+	// confirmed were unchanged, saving a download.
+	NotModified int64 `json:"not_modified"`
+	// Retries is the number of requests sent again after failing.
+	Retries int64 `json:"retries"`
+	// Stale is the number of fetches answered with an expired response
+	// while it was refreshed in the background.
+	Stale int64 `json:"stale"`
+}
+
+type metrics struct {
+	requests    atomic.Int64
+	coalesced   atomic.Int64
+	notModified atomic.Int64
+	retries     atomic.Int64
+	stale       atomic.Int64
+}
+
+func (c *Client) Stats() Stats {
+	return Stats{
+		Requests:    c.metrics.requests.Load(),
+		Coalesced:   c.metrics.coalesced.Load(),
+		NotModified: c.metrics.notModified.Load(),
+		Retries:     c.metrics.retries.Load(),
+		Stale:       c.metrics.stale.Load(),
 	}
 }
@@ -0,0 +1,151 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestClientOptions(t *testing.T) {
+	var gotPath, gotAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"name":"bulbasaur"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL+"/api/v2/"), WithUserAgent("pokedex-test"))
+	defer client.Close()
+	p, err := client.GetPokemon("bulbasaur")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "bulbasaur" {
+		t.Errorf("unexpected pokemon: %s", p.Name)
+	}
+	if gotPath != "/api/v2/pokemon/bulbasaur" {
+		t.Errorf("unexpected path: %s", gotPath)
+	}
+	if gotAgent != "pokedex-test" {
+		t.Errorf("unexpected user agent: %s", gotAgent)
+	}
+}
+
+func TestGetPokemonSpecies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pokemon-species/pikachu" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{
+			"name": "pikachu",
+			"capture_rate": 190,
+			"gender_rate": 4,
+			"growth_rate": {"name": "medium"},
+			"egg_groups": [{"name": "ground"}, {"name": "fairy"}],
+			"flavor_text_entries": [
+				{"flavor_text": "old\ntext", "language": {"name": "en"}},
+				{"flavor_text": "Quand plusieurs", "language": {"name": "fr"}},
+				{"flavor_text": "When several of\nthese\fPOKéMON gather", "language": {"name": "en"}}
+			]
+		}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	defer client.Close()
+	s, err := client.GetPokemonSpecies("pikachu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.CaptureRate != 190 || s.GrowthRate.Name != "medium" || len(s.EggGroups) != 2 {
+		t.Errorf("unexpected species: %+v", s)
+	}
+	if s.FemaleRatio() != 0.5 {
+		t.Errorf("unexpected female ratio: %v", s.FemaleRatio())
+	}
+	if text := s.FlavorText("en"); text != "When several of these POKéMON gather" {
+		t.Errorf("unexpected flavor text: %q", text)
+	}
+}
+
+func TestGetMovesContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/move/tackle":
+			w.Write([]byte(`{"name": "tackle", "power": 40}`))
+		case "/move/growl":
+			w.Write([]byte(`{"name": "growl", "power": null}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	defer client.Close()
+	moves, err := client.GetMovesContext(context.Background(), []string{"growl", "tackle"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(moves) != 2 || moves[0].Name != "growl" || moves[1].Name != "tackle" {
+		t.Fatalf("moves out of order: %+v", moves)
+	}
+	if moves[0].Power != nil || moves[1].Power == nil || *moves[1].Power != 40 {
+		t.Errorf("unexpected power values: %v, %v", moves[0].Power, moves[1].Power)
+	}
+
+	_, err = client.GetMovesContext(context.Background(), []string{"tackle", "splash"})
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected a NotFoundError, got %v", err)
+	}
+}
+
+func TestGrowthRateLevels(t *testing.T) {
+	g := GrowthRate{Levels: []GrowthRateExperienceLevel{
+		{Level: 1, Experience: 0},
+		{Level: 2, Experience: 8},
+		{Level: 3, Experience: 27},
+		{Level: 4, Experience: 64},
+	}}
+
+	cases := []struct {
+		experience int
+		level      int
+	}{
+		{experience: 0, level: 1},
+		{experience: 26, level: 2},
+		{experience: 27, level: 3},
+		{experience: 1000, level: 4},
+	}
+	for _, c := range cases {
+		if got := g.LevelAt(c.experience); got != c.level {
+			t.Errorf("Result: %d, does not equal expected: %d for %d experience", got, c.level, c.experience)
+		}
+	}
+	if got := g.ExperienceAt(3); got != 27 {
+		t.Errorf("Result: %d, does not equal expected: 27", got)
+	}
+}
+
+func TestClientCloseStopsCache(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		client := NewClient(WithCacheInterval(time.Millisecond))
+		client.Close()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected at most %d goroutines, have %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
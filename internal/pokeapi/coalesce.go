@@ -0,0 +1,52 @@
+package pokeapi
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup deduplicates concurrent fetches of the same URL, so callers
+// arriving while a request is in flight share its response instead of
+// sending their own.
+type flightGroup struct {
+	mux     sync.Mutex
+	flights map[string]*flight
+}
+
+type flight struct {
+	done chan struct{}
+	dat  []byte
+	err  error
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{flights: map[string]*flight{}}
+}
+
+// do runs fn for key unless a call for key is already in flight, in which
+// case it waits for that call's result. shared reports whether the result
+// came from another caller. Waiting stops early when ctx is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() ([]byte, error)) (dat []byte, shared bool, err error) {
+	g.mux.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.mux.Unlock()
+		select {
+		case <-f.done:
+			return f.dat, true, f.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mux.Unlock()
+
+	defer func() {
+		g.mux.Lock()
+		delete(g.flights, key)
+		g.mux.Unlock()
+		close(f.done)
+	}()
+	f.dat, f.err = fn()
+	return f.dat, false, f.err
+}
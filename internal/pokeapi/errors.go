@@ -0,0 +1,64 @@
+package pokeapi
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotFoundError is returned when PokeAPI has no resource at URL.
+type NotFoundError struct {
+	URL string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("resource not found: %s", e.URL)
+}
+
+// RateLimitError is returned when PokeAPI answers 429 Too Many Requests.
+// RetryAfter is zero when the response did not say how long to wait.
+type RateLimitError struct {
+	URL        string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited by %s, retry after %s", e.URL, e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limited by %s", e.URL)
+}
+
+// ServerError is returned for 5xx responses from PokeAPI.
+type ServerError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("upstream error %d from %s", e.StatusCode, e.URL)
+}
+
+// StatusError is returned for any other non-2xx response.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s", e.StatusCode, e.URL)
+}
+
+// DecodeError is returned when a response body is not the expected JSON.
+type DecodeError struct {
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding response from %s (status %d): %v", e.URL, e.StatusCode, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
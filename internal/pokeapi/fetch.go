@@ -0,0 +1,209 @@
+package pokeapi
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rasmussecher/pokedex/internal/pokecache"
+)
+
+// Fetch retrieves the resource at url and decodes it into a T. Responses are
+// served from the client cache when present and added to it once they decode.
+// Go does not allow type parameters on methods, so the client is passed in.
+func Fetch[T any](c *Client, url string) (T, error) {
+	return FetchContext[T](context.Background(), c, url)
+}
+
+// FetchContext is like Fetch but aborts the request when ctx is done.
+// Concurrent fetches of the same URL share a single request, and expired
+// cached responses are revalidated rather than downloaded again.
+func FetchContext[T any](ctx context.Context, c *Client, url string) (T, error) {
+	var res T
+
+	cached, ok := c.cache.Lookup(url)
+	if ok {
+		fresh := cached.Fresh(time.Now())
+		if fresh || c.serveStale(cached) {
+			if err := json.Unmarshal(cached.Value, &res); err == nil {
+				if !fresh {
+					c.metrics.stale.Add(1)
+					c.refreshInBackground(url, cached)
+				}
+				return res, nil
+			}
+			// Don't revalidate a response we can't use.
+			cached = pokecache.Entry{}
+		}
+	}
+
+	for {
+		dat, shared, err := c.flights.do(ctx, url, func() ([]byte, error) {
+			e, err := c.revalidate(ctx, url, cached)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(e.Value, &res); err != nil {
+				return nil, &DecodeError{URL: url, StatusCode: http.StatusOK, Err: err}
+			}
+			c.cache.AddEntry(url, e)
+			return e.Value, nil
+		})
+		if !shared {
+			return res, err
+		}
+		if isContextError(err) {
+			if ctx.Err() != nil {
+				return res, ctx.Err()
+			}
+			// The request we waited on was cancelled by its own caller,
+			// so make our own.
+			continue
+		}
+
+		c.metrics.coalesced.Add(1)
+		if err != nil {
+			return res, err
+		}
+		if err := json.Unmarshal(dat, &res); err != nil {
+			return res, &DecodeError{URL: url, StatusCode: http.StatusOK, Err: err}
+		}
+		return res, nil
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// serveStale reports whether an expired response is recent enough to be
+// served while it is refreshed.
+func (c *Client) serveStale(e pokecache.Entry) bool {
+	return c.staleWhileRevalidate > 0 && time.Since(e.ExpiresAt) < c.staleWhileRevalidate
+}
+
+// refreshInBackground revalidates a stale response without making the caller
+// wait. It joins a request for url that is already in flight.
+func (c *Client) refreshInBackground(url string, stale pokecache.Entry) {
+	c.refreshes.Add(1)
+	go func() {
+		defer c.refreshes.Done()
+		ctx := context.Background()
+		c.flights.do(ctx, url, func() ([]byte, error) {
+			e, err := c.revalidate(ctx, url, stale)
+			if err != nil {
+				return nil, err
+			}
+			if !json.Valid(e.Value) {
+				return nil, &DecodeError{URL: url, StatusCode: http.StatusOK, Err: errors.New("invalid JSON")}
+			}
+			c.cache.AddEntry(url, e)
+			return e.Value, nil
+		})
+	}()
+}
+
+// revalidate fetches url, sending the validators of the cached entry so that
+// an unchanged resource costs a 304 instead of the whole body. Failures are
+// retried according to the client's retry policy, and every attempt waits
+// for the rate limiter. The returned entry has not been added to the cache.
+func (c *Client) revalidate(ctx context.Context, url string, cached pokecache.Entry) (pokecache.Entry, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return pokecache.Entry{}, err
+		}
+		e, err := c.send(ctx, url, cached)
+		if err == nil {
+			return e, nil
+		}
+		delay, ok := c.retry.delay(ctx, err, attempt)
+		if !ok {
+			return pokecache.Entry{}, err
+		}
+		c.metrics.retries.Add(1)
+		if err := sleep(ctx, delay); err != nil {
+			return pokecache.Entry{}, err
+		}
+	}
+}
+
+// send makes a single conditional GET for url.
+func (c *Client) send(ctx context.Context, url string, cached pokecache.Entry) (pokecache.Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return pokecache.Entry{}, err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if cached.Value != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	c.metrics.requests.Add(1)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return pokecache.Entry{}, fmt.Errorf("requesting %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	e := pokecache.Entry{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if resp.StatusCode == http.StatusNotModified && cached.Value != nil {
+		c.metrics.notModified.Add(1)
+		e.Value = cached.Value
+		e.ETag = cmp.Or(e.ETag, cached.ETag)
+		e.LastModified = cmp.Or(e.LastModified, cached.LastModified)
+		return e, nil
+	}
+	if err := checkStatus(url, resp); err != nil {
+		return pokecache.Entry{}, err
+	}
+
+	e.Value, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return pokecache.Entry{}, fmt.Errorf("reading %s: %w", url, err)
+	}
+	return e, nil
+}
+
+func checkStatus(url string, resp *http.Response) error {
+	switch code := resp.StatusCode; {
+	case code < 300:
+		return nil
+	case code == http.StatusNotFound:
+		return &NotFoundError{URL: url}
+	case code == http.StatusTooManyRequests:
+		return &RateLimitError{URL: url, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	case code >= 500:
+		return &ServerError{URL: url, StatusCode: code}
+	default:
+		return &StatusError{URL: url, StatusCode: code}
+	}
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
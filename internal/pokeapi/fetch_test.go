@@ -0,0 +1,341 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rasmussecher/pokedex/internal/pokecache"
+)
+
+func TestFetchCaches(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`{"name":"pikachu","id":25}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithTimeout(time.Second))
+	defer client.Close()
+	for i := 0; i < 2; i++ {
+		p, err := Fetch[Pokemon](&client, srv.URL+"/pokemon/pikachu")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Name != "pikachu" || p.ID != 25 {
+			t.Errorf("unexpected pokemon: %s %d", p.Name, p.ID)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected 1 upstream request, got %d", hits)
+	}
+}
+
+func TestFetchStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithTimeout(time.Second))
+	defer client.Close()
+	_, err := Fetch[Pokemon](&client, srv.URL+"/pokemon/missingno")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if notFound.URL != srv.URL+"/pokemon/missingno" {
+		t.Errorf("unexpected url: %s", notFound.URL)
+	}
+}
+
+func TestFetchErrorTypes(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		check  func(error) bool
+	}{
+		{
+			status: http.StatusTooManyRequests,
+			check: func(err error) bool {
+				var e *RateLimitError
+				return errors.As(err, &e) && e.RetryAfter == 2*time.Second
+			},
+		},
+		{
+			status: http.StatusBadGateway,
+			check: func(err error) bool {
+				var e *ServerError
+				return errors.As(err, &e) && e.StatusCode == 502
+			},
+		},
+		{
+			status: http.StatusForbidden,
+			check: func(err error) bool {
+				var e *StatusError
+				return errors.As(err, &e) && e.StatusCode == 403
+			},
+		},
+		{
+			status: http.StatusOK,
+			body:   "not json",
+			check: func(err error) bool {
+				var e *DecodeError
+				return errors.As(err, &e)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("status %d", c.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "2")
+				w.WriteHeader(c.status)
+				w.Write([]byte(c.body))
+			}))
+			defer srv.Close()
+
+			client := NewClient(WithTimeout(time.Second), WithRetries(0))
+			defer client.Close()
+			_, err := Fetch[Pokemon](&client, srv.URL)
+			if !c.check(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFetchContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := NewClient(WithTimeout(5 * time.Second))
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := FetchContext[Pokemon](ctx, &client, srv.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestFetchCoalesces(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		w.Write([]byte(`{"name":"pikachu","id":25}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithTimeout(5 * time.Second))
+	defer client.Close()
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := Fetch[Pokemon](&client, srv.URL+"/pokemon/pikachu")
+			if err == nil && p.Name != "pikachu" {
+				err = fmt.Errorf("unexpected pokemon: %s", p.Name)
+			}
+			errs <- err
+		}()
+	}
+	// Give every caller time to join the request before it completes.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected 1 upstream request, got %d", hits.Load())
+	}
+	if stats := client.Stats(); stats.Requests != 1 || stats.Coalesced != callers-1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestFetchCoalescedCallerOutlivesCancelledLeader(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"name":"pikachu","id":25}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithTimeout(5 * time.Second))
+	defer client.Close()
+	url := srv.URL + "/pokemon/pikachu"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error)
+	go func() {
+		_, err := FetchContext[Pokemon](ctx, &client, url)
+		leaderDone <- err
+	}()
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	followerDone := make(chan error)
+	go func() {
+		_, err := Fetch[Pokemon](&client, url)
+		followerDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-leaderDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the leader to be cancelled, got %v", err)
+	}
+	if err := <-followerDone; err != nil {
+		t.Errorf("expected the follower to retry, got %v", err)
+	}
+}
+
+func TestFetchRevalidates(t *testing.T) {
+	var hits, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"name":"pikachu","id":25}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithTimeout(time.Second), WithCacheInterval(time.Millisecond))
+	defer client.Close()
+	for i := 0; i < 2; i++ {
+		p, err := Fetch[Pokemon](&client, srv.URL+"/pokemon/pikachu")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Name != "pikachu" {
+			t.Errorf("unexpected pokemon: %s", p.Name)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if hits.Load() != 2 || notModified.Load() != 1 {
+		t.Errorf("expected 2 requests with 1 revalidated, got %d and %d", hits.Load(), notModified.Load())
+	}
+	if stats := client.Stats(); stats.NotModified != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestFetchStaleWhileRevalidate(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Write([]byte(`{"name":"pikachu","id":25}`))
+			return
+		}
+		<-release
+		w.Write([]byte(`{"name":"raichu","id":26}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(
+		WithTimeout(time.Second),
+		WithCacheInterval(time.Millisecond),
+		WithStaleWhileRevalidate(time.Hour),
+	)
+	url := srv.URL + "/pokemon/pikachu"
+	if _, err := Fetch[Pokemon](&client, url); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The refresh is blocked, so this must be answered from the cache.
+	p, err := Fetch[Pokemon](&client, url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "pikachu" {
+		t.Errorf("expected the stale response, got %s", p.Name)
+	}
+
+	close(release)
+	client.Close()
+	e, ok := client.Cache().Lookup(url)
+	if !ok || string(e.Value) != `{"name":"raichu","id":26}` {
+		t.Errorf("expected the refreshed response to be cached, got %s", e.Value)
+	}
+	if stats := client.Stats(); stats.Stale != 1 || stats.Requests != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestFetchRevalidatesFromDisk(t *testing.T) {
+	var notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"name":"pikachu","id":25}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	url := srv.URL + "/pokemon/pikachu"
+	// Each client stands in for a run of the program sharing one cache
+	// directory.
+	for i := 0; i < 2; i++ {
+		disk, err := pokecache.NewDiskStore(dir, time.Millisecond, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cache := pokecache.NewCacheWithDisk(time.Minute, disk, pokecache.WithStaleRetention(time.Hour))
+		client := NewClient(WithCache(cache))
+		p, err := Fetch[Pokemon](&client, url)
+		client.Close()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Name != "pikachu" {
+			t.Errorf("unexpected pokemon: %s", p.Name)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if notModified.Load() != 1 {
+		t.Errorf("expected the second run to revalidate, got %d 304s", notModified.Load())
+	}
+}
@@ -0,0 +1,54 @@
+package pokeapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket holding up to burst tokens that refill at rate
+// per second. Each request takes a token, waiting for one if the bucket is
+// empty. A nil limiter never waits.
+type limiter struct {
+	mux    sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	b := float64(max(burst, 1))
+	return &limiter{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+// wait takes a token, blocking until one is available or ctx is done.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mux.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	// Take the token now, even if that leaves the bucket in debt, so
+	// waiting callers are served in the order they arrived.
+	l.tokens--
+	debt := -l.tokens
+	l.mux.Unlock()
+
+	if debt <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, time.Duration(debt/l.rate*float64(time.Second))); err != nil {
+		l.mux.Lock()
+		l.tokens++
+		l.mux.Unlock()
+		return err
+	}
+	return nil
+}
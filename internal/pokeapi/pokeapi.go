@@ -1,10 +1,9 @@
 package pokeapi
 
 import (
-	"encoding/json"
-	"io"
-	"log"
-	"net/http"
+	"context"
+	"errors"
+	"sync"
 )
 
 type ListResponse struct {
@@ -17,37 +16,12 @@ type ListResponse struct {
 	} `json:"results"`
 }
 
-// TODO - These request functions should be generic instead.
-func (c *Client) GetList(url string) ListResponse {
-	if val, ok := c.cache.Get(url); ok {
-		locationsResp := ListResponse{}
-		err := json.Unmarshal(val, &locationsResp)
-		if err != nil {
-			log.Fatalf("err")
-			return ListResponse{}
-		}
-
-		return locationsResp
-	}
+func (c *Client) GetList(url string) (ListResponse, error) {
+	return c.GetListContext(context.Background(), url)
+}
 
-	res, err := http.Get(url)
-	if err != nil {
-		log.Fatal(err)
-	}
-	body, err := io.ReadAll(res.Body)
-	res.Body.Close()
-	if res.StatusCode > 299 {
-		log.Fatalf("Response failed with status code: %d and \nbody: %s\n", res.StatusCode, body)
-	}
-	if err != nil {
-		log.Fatal(err)
-	}
-	listRes := ListResponse{}
-	err = json.Unmarshal(body, &listRes)
-	if err != nil {
-		log.Fatal(err)
-	}
-	return listRes
+func (c *Client) GetListContext(ctx context.Context, url string) (ListResponse, error) {
+	return FetchContext[ListResponse](ctx, c, url)
 }
 
 func (listRes *ListResponse) ExtractNames() []string {
@@ -58,81 +32,178 @@ func (listRes *ListResponse) ExtractNames() []string {
 	return names
 }
 
+// PokemonEncounterList is a location area with the Pokemon that can be
+// encountered there.
 type PokemonEncounterList struct {
-	Encounters []struct {
-		Pokemon struct {
-			Name string `json:"name"`
-		} `json:"pokemon"`
-	} `json:"pokemon_encounters"`
+	ID         int                `json:"id"`
+	Name       string             `json:"name"`
+	Location   NamedAPIResource   `json:"location"`
+	Encounters []PokemonEncounter `json:"pokemon_encounters"`
 }
 
-func (c *Client) GetPokemonsForArea(url string) PokemonEncounterList {
-	if val, ok := c.cache.Get(url); ok {
-		locationsResp := PokemonEncounterList{}
-		err := json.Unmarshal(val, &locationsResp)
-		if err != nil {
-			log.Fatalf("err")
-			return PokemonEncounterList{}
-		}
+type PokemonEncounter struct {
+	Pokemon        NamedAPIResource         `json:"pokemon"`
+	VersionDetails []VersionEncounterDetail `json:"version_details"`
+}
 
-		return locationsResp
-	}
+type VersionEncounterDetail struct {
+	Version          NamedAPIResource `json:"version"`
+	MaxChance        int              `json:"max_chance"`
+	EncounterDetails []Encounter      `json:"encounter_details"`
+}
 
-	res, err := http.Get(url)
-	if err != nil {
-		log.Fatal(err)
-	}
-	body, err := io.ReadAll(res.Body)
-	res.Body.Close()
-	if res.StatusCode > 299 {
-		log.Fatalf("Response failed with status code: %d and \nbody: %s\n", res.StatusCode, body)
-	}
-	if err != nil {
-		log.Fatal(err)
-	}
-	listRes := PokemonEncounterList{}
-	err = json.Unmarshal(body, &listRes)
-	if err != nil {
-		log.Fatal(err)
-	}
-	return listRes
+type Encounter struct {
+	MinLevel        int                `json:"min_level"`
+	MaxLevel        int                `json:"max_level"`
+	ConditionValues []NamedAPIResource `json:"condition_values"`
+	Chance          int                `json:"chance"`
+	Method          NamedAPIResource   `json:"method"`
+}
+
+func (c *Client) GetPokemonsForArea(areaName string) (PokemonEncounterList, error) {
+	return c.GetPokemonsForAreaContext(context.Background(), areaName)
+}
+
+func (c *Client) GetPokemonsForAreaContext(ctx context.Context, areaName string) (PokemonEncounterList, error) {
+	return FetchContext[PokemonEncounterList](ctx, c, c.Endpoint("location-area", areaName))
 }
 
 func (c *Client) GetPokemon(pokemonName string) (Pokemon, error) {
-	url := "https://pokeapi.co/api/v2/pokemon/" + pokemonName
+	return c.GetPokemonContext(context.Background(), pokemonName)
+}
+
+func (c *Client) GetPokemonContext(ctx context.Context, pokemonName string) (Pokemon, error) {
+	return FetchContext[Pokemon](ctx, c, c.Endpoint("pokemon", pokemonName))
+}
+
+func (c *Client) GetPokemonSpecies(speciesName string) (PokemonSpecies, error) {
+	return c.GetPokemonSpeciesContext(context.Background(), speciesName)
+}
+
+func (c *Client) GetPokemonSpeciesContext(ctx context.Context, speciesName string) (PokemonSpecies, error) {
+	return FetchContext[PokemonSpecies](ctx, c, c.Endpoint("pokemon-species", speciesName))
+}
+
+func (c *Client) GetEvolutionChain(id string) (EvolutionChain, error) {
+	return c.GetEvolutionChainContext(context.Background(), id)
+}
+
+func (c *Client) GetEvolutionChainContext(ctx context.Context, id string) (EvolutionChain, error) {
+	return FetchContext[EvolutionChain](ctx, c, c.Endpoint("evolution-chain", id))
+}
+
+func (c *Client) GetRegion(regionName string) (Region, error) {
+	return c.GetRegionContext(context.Background(), regionName)
+}
+
+func (c *Client) GetRegionContext(ctx context.Context, regionName string) (Region, error) {
+	return FetchContext[Region](ctx, c, c.Endpoint("region", regionName))
+}
+
+func (c *Client) GetLocation(locationName string) (Location, error) {
+	return c.GetLocationContext(context.Background(), locationName)
+}
+
+func (c *Client) GetLocationContext(ctx context.Context, locationName string) (Location, error) {
+	return FetchContext[Location](ctx, c, c.Endpoint("location", locationName))
+}
+
+func (c *Client) GetType(typeName string) (Type, error) {
+	return c.GetTypeContext(context.Background(), typeName)
+}
+
+func (c *Client) GetTypeContext(ctx context.Context, typeName string) (Type, error) {
+	return FetchContext[Type](ctx, c, c.Endpoint("type", typeName))
+}
+
+func (c *Client) GetMove(moveName string) (Move, error) {
+	return c.GetMoveContext(context.Background(), moveName)
+}
+
+func (c *Client) GetMoveContext(ctx context.Context, moveName string) (Move, error) {
+	return FetchContext[Move](ctx, c, c.Endpoint("move", moveName))
+}
+
+// maxConcurrentFetches bounds the requests made at once by batch lookups.
+const maxConcurrentFetches = 8
+
+// GetMovesContext fetches several moves concurrently, returning them in the
+// order of names. It stops at the first error.
+func (c *Client) GetMovesContext(ctx context.Context, names []string) ([]Move, error) {
+	return fetchAll(ctx, names, c.GetMoveContext)
+}
 
-	if val, ok := c.cache.Get(url); ok {
-		pokemonResp := Pokemon{}
-		err := json.Unmarshal(val, &pokemonResp)
+// fetchAll calls get for every name, at most maxConcurrentFetches at a time,
+// and cancels the rest once one fails.
+func fetchAll[T any](ctx context.Context, names []string, get func(context.Context, string) (T, error)) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	res := make([]T, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, maxConcurrentFetches)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			res[i], errs[i] = get(ctx, name)
+			if errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+	}
+	for _, err := range errs {
 		if err != nil {
-			return Pokemon{}, err
+			return nil, err
 		}
-		return pokemonResp, nil
 	}
+	return res, nil
+}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return Pokemon{}, err
-	}
+func (c *Client) GetGrowthRate(name string) (GrowthRate, error) {
+	return c.GetGrowthRateContext(context.Background(), name)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return Pokemon{}, err
-	}
-	defer resp.Body.Close()
+func (c *Client) GetGrowthRateContext(ctx context.Context, name string) (GrowthRate, error) {
+	return FetchContext[GrowthRate](ctx, c, c.Endpoint("growth-rate", name))
+}
 
-	dat, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return Pokemon{}, err
-	}
+func (c *Client) GetNature(name string) (Nature, error) {
+	return c.GetNatureContext(context.Background(), name)
+}
 
-	pokemonResp := Pokemon{}
-	err = json.Unmarshal(dat, &pokemonResp)
-	if err != nil {
-		return Pokemon{}, err
-	}
+func (c *Client) GetNatureContext(ctx context.Context, name string) (Nature, error) {
+	return FetchContext[Nature](ctx, c, c.Endpoint("nature", name))
+}
+
+func (c *Client) GetPokedex(name string) (Pokedex, error) {
+	return c.GetPokedexContext(context.Background(), name)
+}
+
+func (c *Client) GetPokedexContext(ctx context.Context, name string) (Pokedex, error) {
+	return FetchContext[Pokedex](ctx, c, c.Endpoint("pokedex", name))
+}
 
-	c.cache.Add(url, dat)
+func (c *Client) GetGeneration(name string) (Generation, error) {
+	return c.GetGenerationContext(context.Background(), name)
+}
 
-	return pokemonResp, nil
+func (c *Client) GetGenerationContext(ctx context.Context, name string) (Generation, error) {
+	return FetchContext[Generation](ctx, c, c.Endpoint("generation", name))
 }
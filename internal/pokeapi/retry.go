@@ -0,0 +1,77 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/url"
+	"time"
+)
+
+const (
+	DefaultRetries         = 3
+	DefaultRetryBackoff    = 250 * time.Millisecond
+	DefaultMaxRetryBackoff = 5 * time.Second
+	// maxRetryAfter is the longest Retry-After the client waits out; a
+	// longer one is returned to the caller as a RateLimitError.
+	maxRetryAfter = time.Minute
+)
+
+// retryPolicy decides whether and when a failed GET is sent again.
+type retryPolicy struct {
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// delay returns how long to wait before retrying after err on the given
+// zero-based attempt, or false if the request should not be retried. Only
+// server errors, rate limiting and transport failures such as timeouts are
+// retried, and never once ctx is done.
+func (p retryPolicy) delay(ctx context.Context, err error, attempt int) (time.Duration, bool) {
+	if attempt >= p.retries || ctx.Err() != nil {
+		return 0, false
+	}
+
+	var rateLimit *RateLimitError
+	var server *ServerError
+	var transport *url.Error
+	switch {
+	case errors.As(err, &rateLimit):
+		if rateLimit.RetryAfter > maxRetryAfter {
+			return 0, false
+		}
+		if rateLimit.RetryAfter > 0 {
+			return rateLimit.RetryAfter, true
+		}
+	case errors.As(err, &server), errors.As(err, &transport):
+	default:
+		return 0, false
+	}
+	return p.backoffFor(attempt), true
+}
+
+// backoffFor picks a random delay up to backoff*2^attempt, capped at
+// maxBackoff, so clients that failed together don't retry together.
+func (p retryPolicy) backoffFor(attempt int) time.Duration {
+	limit := p.maxBackoff
+	if attempt < 30 {
+		limit = min(p.backoff<<attempt, p.maxBackoff)
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit) + 1
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,106 @@
+package pokeapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	policy := retryPolicy{retries: 2, backoff: 100 * time.Millisecond, maxBackoff: time.Second}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		name    string
+		ctx     context.Context
+		err     error
+		attempt int
+		retry   bool
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "server error", err: &ServerError{StatusCode: 503}, retry: true, min: 1, max: 100 * time.Millisecond},
+		{name: "backoff grows", err: &ServerError{StatusCode: 503}, attempt: 1, retry: true, min: 1, max: 200 * time.Millisecond},
+		{name: "timeout", err: &url.Error{Op: "Get", Err: context.DeadlineExceeded}, retry: true, min: 1, max: 100 * time.Millisecond},
+		{name: "retry after", err: &RateLimitError{RetryAfter: 2 * time.Second}, retry: true, min: 2 * time.Second, max: 2 * time.Second},
+		{name: "rate limited", err: &RateLimitError{}, retry: true, min: 1, max: 100 * time.Millisecond},
+		{name: "retry after too long", err: &RateLimitError{RetryAfter: time.Hour}},
+		{name: "not found", err: &NotFoundError{}},
+		{name: "decode error", err: &DecodeError{}},
+		{name: "out of retries", err: &ServerError{StatusCode: 503}, attempt: 2},
+		{name: "cancelled", ctx: cancelled, err: &ServerError{StatusCode: 503}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := c.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			delay, retry := policy.delay(ctx, c.err, c.attempt)
+			if retry != c.retry {
+				t.Fatalf("Result: %v, does not equal expected: %v", retry, c.retry)
+			}
+			if retry && (delay < c.min || delay > c.max) {
+				t.Errorf("expected a delay between %s and %s, got %s", c.min, c.max, delay)
+			}
+		})
+	}
+}
+
+func TestFetchRetries(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"name":"pikachu","id":25}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithTimeout(time.Second), WithRetryBackoff(time.Millisecond, 5*time.Millisecond))
+	defer client.Close()
+	p, err := Fetch[Pokemon](&client, srv.URL+"/pokemon/pikachu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "pikachu" {
+		t.Errorf("unexpected pokemon: %s", p.Name)
+	}
+	if stats := client.Stats(); stats.Requests != 3 || stats.Retries != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	var unlimited *limiter
+	if err := unlimited.wait(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	l := newLimiter(100, 2)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// The burst covers two requests; the other four wait 10ms each.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("expected the limiter to wait about 40ms, waited %s", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	slow := newLimiter(0.1, 1)
+	slow.wait(ctx)
+	if err := slow.wait(ctx); err == nil {
+		t.Errorf("expected waiting to stop when the context is done")
+	}
+}
@@ -0,0 +1,10 @@
+package pokeapi
+
+type NamedAPIResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type APIResource struct {
+	URL string `json:"url"`
+}
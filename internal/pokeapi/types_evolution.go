@@ -0,0 +1,50 @@
+package pokeapi
+
+type EvolutionChain struct {
+	ID              int               `json:"id"`
+	BabyTriggerItem *NamedAPIResource `json:"baby_trigger_item"`
+	Chain           ChainLink         `json:"chain"`
+}
+
+type ChainLink struct {
+	IsBaby           bool              `json:"is_baby"`
+	Species          NamedAPIResource  `json:"species"`
+	EvolutionDetails []EvolutionDetail `json:"evolution_details"`
+	EvolvesTo        []ChainLink       `json:"evolves_to"`
+}
+
+// EvolutionDetail describes one way to evolve into a ChainLink. Nullable
+// fields in the API are pointers so "not required" differs from zero.
+type EvolutionDetail struct {
+	Trigger               NamedAPIResource  `json:"trigger"`
+	Item                  *NamedAPIResource `json:"item"`
+	HeldItem              *NamedAPIResource `json:"held_item"`
+	KnownMove             *NamedAPIResource `json:"known_move"`
+	KnownMoveType         *NamedAPIResource `json:"known_move_type"`
+	Location              *NamedAPIResource `json:"location"`
+	PartySpecies          *NamedAPIResource `json:"party_species"`
+	PartyType             *NamedAPIResource `json:"party_type"`
+	TradeSpecies          *NamedAPIResource `json:"trade_species"`
+	Gender                *int              `json:"gender"`
+	MinLevel              *int              `json:"min_level"`
+	MinHappiness          *int              `json:"min_happiness"`
+	MinBeauty             *int              `json:"min_beauty"`
+	MinAffection          *int              `json:"min_affection"`
+	RelativePhysicalStats *int              `json:"relative_physical_stats"`
+	NeedsOverworldRain    bool              `json:"needs_overworld_rain"`
+	TurnUpsideDown        bool              `json:"turn_upside_down"`
+	TimeOfDay             string            `json:"time_of_day"`
+}
+
+// Find returns the link for the named species within the chain.
+func (l *ChainLink) Find(species string) (*ChainLink, bool) {
+	if l.Species.Name == species {
+		return l, true
+	}
+	for i := range l.EvolvesTo {
+		if found, ok := l.EvolvesTo[i].Find(species); ok {
+			return found, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,44 @@
+package pokeapi
+
+type GrowthRate struct {
+	ID      int                         `json:"id"`
+	Name    string                      `json:"name"`
+	Formula string                      `json:"formula"`
+	Levels  []GrowthRateExperienceLevel `json:"levels"`
+}
+
+type GrowthRateExperienceLevel struct {
+	Level      int `json:"level"`
+	Experience int `json:"experience"`
+}
+
+// ExperienceAt returns the total experience needed to reach level.
+func (g *GrowthRate) ExperienceAt(level int) int {
+	for _, l := range g.Levels {
+		if l.Level == level {
+			return l.Experience
+		}
+	}
+	return 0
+}
+
+// LevelAt returns the highest level reached with the given total
+// experience.
+func (g *GrowthRate) LevelAt(experience int) int {
+	level := 1
+	for _, l := range g.Levels {
+		if l.Experience <= experience && l.Level > level {
+			level = l.Level
+		}
+	}
+	return level
+}
+
+// Nature raises IncreasedStat and lowers DecreasedStat; both are empty for
+// neutral natures.
+type Nature struct {
+	ID            int              `json:"id"`
+	Name          string           `json:"name"`
+	DecreasedStat NamedAPIResource `json:"decreased_stat"`
+	IncreasedStat NamedAPIResource `json:"increased_stat"`
+}
@@ -0,0 +1,17 @@
+package pokeapi
+
+type Region struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	Locations      []NamedAPIResource `json:"locations"`
+	MainGeneration NamedAPIResource   `json:"main_generation"`
+	Pokedexes      []NamedAPIResource `json:"pokedexes"`
+	VersionGroups  []NamedAPIResource `json:"version_groups"`
+}
+
+type Location struct {
+	ID     int                `json:"id"`
+	Name   string             `json:"name"`
+	Region NamedAPIResource   `json:"region"`
+	Areas  []NamedAPIResource `json:"areas"`
+}
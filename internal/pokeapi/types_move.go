@@ -0,0 +1,31 @@
+package pokeapi
+
+type Move struct {
+	ID           int              `json:"id"`
+	Name         string           `json:"name"`
+	Accuracy     *int             `json:"accuracy"`
+	Power        *int             `json:"power"`
+	PP           *int             `json:"pp"`
+	Priority     int              `json:"priority"`
+	EffectChance *int             `json:"effect_chance"`
+	Type         NamedAPIResource `json:"type"`
+	DamageClass  NamedAPIResource `json:"damage_class"`
+	Generation   NamedAPIResource `json:"generation"`
+	Target       NamedAPIResource `json:"target"`
+	Meta         *struct {
+		Ailment       NamedAPIResource `json:"ailment"`
+		Category      NamedAPIResource `json:"category"`
+		MinHits       *int             `json:"min_hits"`
+		MaxHits       *int             `json:"max_hits"`
+		Drain         int              `json:"drain"`
+		Healing       int              `json:"healing"`
+		CritRate      int              `json:"crit_rate"`
+		AilmentChance int              `json:"ailment_chance"`
+		FlinchChance  int              `json:"flinch_chance"`
+	} `json:"meta"`
+	EffectEntries []struct {
+		Effect      string           `json:"effect"`
+		ShortEffect string           `json:"short_effect"`
+		Language    NamedAPIResource `json:"language"`
+	} `json:"effect_entries"`
+}
@@ -0,0 +1,37 @@
+package pokeapi
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+type Pokedex struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	IsMainSeries   bool               `json:"is_main_series"`
+	Region         NamedAPIResource   `json:"region"`
+	VersionGroups  []NamedAPIResource `json:"version_groups"`
+	PokemonEntries []struct {
+		EntryNumber    int              `json:"entry_number"`
+		PokemonSpecies NamedAPIResource `json:"pokemon_species"`
+	} `json:"pokemon_entries"`
+}
+
+type Generation struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	MainRegion     NamedAPIResource   `json:"main_region"`
+	PokemonSpecies []NamedAPIResource `json:"pokemon_species"`
+	VersionGroups  []NamedAPIResource `json:"version_groups"`
+}
+
+// ResourceID returns the id at the end of a resource URL, or 0 if it has
+// none. Species ids are their national Pokedex numbers.
+func ResourceID(url string) int {
+	id, err := strconv.Atoi(path.Base(strings.TrimRight(url, "/")))
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,74 @@
+package pokeapi
+
+import (
+	"path"
+	"strings"
+)
+
+type PokemonSpecies struct {
+	ID                 int                `json:"id"`
+	Name               string             `json:"name"`
+	Order              int                `json:"order"`
+	CaptureRate        int                `json:"capture_rate"`
+	BaseHappiness      int                `json:"base_happiness"`
+	GenderRate         int                `json:"gender_rate"`
+	HatchCounter       int                `json:"hatch_counter"`
+	IsBaby             bool               `json:"is_baby"`
+	IsLegendary        bool               `json:"is_legendary"`
+	IsMythical         bool               `json:"is_mythical"`
+	GrowthRate         NamedAPIResource   `json:"growth_rate"`
+	EggGroups          []NamedAPIResource `json:"egg_groups"`
+	Generation         NamedAPIResource   `json:"generation"`
+	Habitat            NamedAPIResource   `json:"habitat"`
+	EvolvesFromSpecies NamedAPIResource   `json:"evolves_from_species"`
+	EvolutionChain     APIResource        `json:"evolution_chain"`
+	FlavorTextEntries  []struct {
+		FlavorText string           `json:"flavor_text"`
+		Language   NamedAPIResource `json:"language"`
+		Version    NamedAPIResource `json:"version"`
+	} `json:"flavor_text_entries"`
+	Genera []struct {
+		Genus    string           `json:"genus"`
+		Language NamedAPIResource `json:"language"`
+	} `json:"genera"`
+	PokedexNumbers []struct {
+		EntryNumber int              `json:"entry_number"`
+		Pokedex     NamedAPIResource `json:"pokedex"`
+	} `json:"pokedex_numbers"`
+}
+
+// FlavorText returns the most recent flavor text in the given language with
+// the line breaks and form feeds from the cartridge text collapsed.
+func (s *PokemonSpecies) FlavorText(language string) string {
+	for i := len(s.FlavorTextEntries) - 1; i >= 0; i-- {
+		e := s.FlavorTextEntries[i]
+		if e.Language.Name == language {
+			return strings.Join(strings.Fields(e.FlavorText), " ")
+		}
+	}
+	return ""
+}
+
+func (s *PokemonSpecies) Genus(language string) string {
+	for _, g := range s.Genera {
+		if g.Language.Name == language {
+			return g.Genus
+		}
+	}
+	return ""
+}
+
+// FemaleRatio returns the chance of a female in eighths converted to a
+// fraction, or -1 for genderless species.
+func (s *PokemonSpecies) FemaleRatio() float64 {
+	if s.GenderRate < 0 {
+		return -1
+	}
+	return float64(s.GenderRate) / 8
+}
+
+// EvolutionChainID returns the id of the species' evolution chain, taken
+// from the last segment of its URL.
+func (s *PokemonSpecies) EvolutionChainID() string {
+	return path.Base(strings.TrimRight(s.EvolutionChain.URL, "/"))
+}
@@ -0,0 +1,18 @@
+package pokeapi
+
+type Type struct {
+	ID              int              `json:"id"`
+	Name            string           `json:"name"`
+	DamageRelations TypeRelations    `json:"damage_relations"`
+	Generation      NamedAPIResource `json:"generation"`
+	MoveDamageClass NamedAPIResource `json:"move_damage_class"`
+}
+
+type TypeRelations struct {
+	NoDamageTo       []NamedAPIResource `json:"no_damage_to"`
+	HalfDamageTo     []NamedAPIResource `json:"half_damage_to"`
+	DoubleDamageTo   []NamedAPIResource `json:"double_damage_to"`
+	NoDamageFrom     []NamedAPIResource `json:"no_damage_from"`
+	HalfDamageFrom   []NamedAPIResource `json:"half_damage_from"`
+	DoubleDamageFrom []NamedAPIResource `json:"double_damage_from"`
+}
@@ -0,0 +1,219 @@
+package pokecache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const diskFileExt = ".cache"
+
+// DiskStore persists cache entries as one file per key so they survive
+// restarts. Entries are fresh for ttl from when they were written; a ttl of
+// 0 keeps them fresh forever. Get treats expired entries as missing, but
+// they are kept so GetEntry can still return them for revalidation, until a
+// Cache finds them past its stale retention and removes them. The oldest
+// files are removed once the directory grows past maxBytes. Unreadable or
+// corrupt files are deleted and reported as misses.
+type DiskStore struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	mux      *sync.Mutex
+}
+
+// never is the expiry of entries in a store without a ttl.
+var never = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+type diskEntry struct {
+	Key          string
+	CreatedAt    time.Time
+	Val          []byte
+	Sum          uint32
+	ETag         string
+	LastModified string
+}
+
+func NewDiskStore(dir string, ttl time.Duration, maxBytes int64) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskStore{
+		dir:      dir,
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		mux:      &sync.Mutex{},
+	}, nil
+}
+
+func (d *DiskStore) Add(key string, value []byte, createdAt time.Time) error {
+	return d.AddEntry(key, Entry{Value: value, CreatedAt: createdAt})
+}
+
+// AddEntry stores e along with its validators. ExpiresAt is not stored;
+// entries on disk live for the store's ttl.
+func (d *DiskStore) AddEntry(key string, e Entry) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(diskEntry{
+		Key:          key,
+		CreatedAt:    e.CreatedAt,
+		Val:          e.Value,
+		Sum:          crc32.ChecksumIEEE(e.Value),
+		ETag:         e.ETag,
+		LastModified: e.LastModified,
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(d.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return d.prune()
+}
+
+func (d *DiskStore) Get(key string) ([]byte, time.Time, bool) {
+	e, ok := d.GetEntry(key)
+	if !ok || !e.Fresh(time.Now()) {
+		return nil, time.Time{}, false
+	}
+	return e.Value, e.CreatedAt, true
+}
+
+// GetEntry is like Get but also returns the stored validators, and returns
+// expired entries too so they can be revalidated. ExpiresAt is set from the
+// store's ttl.
+func (d *DiskStore) GetEntry(key string) (Entry, bool) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	path := d.path(key)
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, false
+	}
+
+	var e diskEntry
+	if err := gob.NewDecoder(bytes.NewReader(dat)).Decode(&e); err != nil ||
+		e.Key != key || crc32.ChecksumIEEE(e.Val) != e.Sum {
+		os.Remove(path)
+		return Entry{}, false
+	}
+	return Entry{
+		Value:        e.Val,
+		ETag:         e.ETag,
+		LastModified: e.LastModified,
+		CreatedAt:    e.CreatedAt,
+		ExpiresAt:    d.expiry(e.CreatedAt),
+	}, true
+}
+
+func (d *DiskStore) expiry(createdAt time.Time) time.Time {
+	if d.ttl <= 0 {
+		return never
+	}
+	return createdAt.Add(d.ttl)
+}
+
+func (d *DiskStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+diskFileExt)
+}
+
+// prune removes the least recently written files until the store fits in
+// maxBytes. Callers must hold d.mux.
+func (d *DiskStore) prune() error {
+	if d.maxBytes <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	files := []file{}
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), diskFileExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{filepath.Join(d.dir, e.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		if total <= d.maxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+	return nil
+}
+
+// Remove deletes the file for key, reporting whether there was one.
+func (d *DiskStore) Remove(key string) bool {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	return os.Remove(d.path(key)) == nil
+}
+
+// Clear deletes every cache file in the store's directory.
+func (d *DiskStore) Clear() error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), diskFileExt) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(d.dir, e.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package pokecache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := NewDiskStore(dir, time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewCacheWithDisk(time.Minute, disk)
+	cache.Add("https://example.com", []byte("testdata"))
+	cache.Close()
+
+	disk, err = NewDiskStore(dir, time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache = NewCacheWithDisk(time.Minute, disk)
+	defer cache.Close()
+	val, ok := cache.Get("https://example.com")
+	if !ok {
+		t.Fatalf("expected to find key")
+	}
+	if string(val) != "testdata" {
+		t.Errorf("expected to find value, got %s", val)
+	}
+}
+
+func TestDiskTTL(t *testing.T) {
+	disk, err := NewDiskStore(t.TempDir(), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disk.Add("https://example.com", []byte("testdata"), time.Now().Add(-2*time.Hour))
+
+	if _, _, ok := disk.Get("https://example.com"); ok {
+		t.Errorf("expected expired entry to be missing")
+	}
+}
+
+func TestDiskCorruption(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := NewDiskStore(dir, time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disk.Add("https://example.com", []byte("testdata"), time.Now())
+
+	path := disk.path("https://example.com")
+	if err := os.WriteFile(path, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, ok := disk.Get("https://example.com"); ok {
+		t.Errorf("expected corrupt entry to be missing")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected corrupt file to be removed")
+	}
+}
+
+func TestDiskSizeCap(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := NewDiskStore(dir, time.Hour, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val := make([]byte, 100)
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		disk.Add(key, val, time.Now())
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+diskFileExt))
+	var total int64
+	for _, f := range files {
+		info, _ := os.Stat(f)
+		total += info.Size()
+	}
+	if total > 300 {
+		t.Errorf("expected at most 300 bytes on disk, got %d", total)
+	}
+	if _, _, ok := disk.Get("e"); !ok {
+		t.Errorf("expected newest entry to be kept")
+	}
+	if _, _, ok := disk.Get("a"); ok {
+		t.Errorf("expected oldest entry to be evicted")
+	}
+}
+
+func TestDiskRemoveClear(t *testing.T) {
+	disk, err := NewDiskStore(t.TempDir(), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewCacheWithDisk(time.Minute, disk)
+	defer cache.Close()
+	cache.Add("a", []byte("testdata"))
+	cache.Add("b", []byte("testdata"))
+
+	if !cache.Remove("a") {
+		t.Errorf("expected a to be removed")
+	}
+	if _, _, ok := disk.Get("a"); ok {
+		t.Errorf("expected a to be removed from disk")
+	}
+
+	if _, err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected b to be cleared from memory and disk")
+	}
+}
+
+func TestDiskValidators(t *testing.T) {
+	disk, err := NewDiskStore(t.TempDir(), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disk.AddEntry("https://example.com", Entry{
+		Value:        []byte("testdata"),
+		ETag:         `"abc"`,
+		LastModified: "Mon, 02 Jan 2006 15:04:05 GMT",
+		CreatedAt:    time.Now(),
+	})
+
+	e, ok := disk.GetEntry("https://example.com")
+	if !ok {
+		t.Fatalf("expected to find key")
+	}
+	if e.ETag != `"abc"` || e.LastModified != "Mon, 02 Jan 2006 15:04:05 GMT" {
+		t.Errorf("unexpected validators %q %q", e.ETag, e.LastModified)
+	}
+}
+
+func TestDiskKeepsExpiredEntriesForRevalidation(t *testing.T) {
+	disk, err := NewDiskStore(t.TempDir(), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disk.AddEntry("https://example.com", Entry{
+		Value:     []byte("testdata"),
+		ETag:      `"abc"`,
+		CreatedAt: time.Now().Add(-90 * time.Minute),
+	})
+
+	cache := NewCacheWithDisk(time.Minute, disk, WithStaleRetention(time.Hour))
+	defer cache.Close()
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected Get to skip the expired entry")
+	}
+	e, ok := cache.Lookup("https://example.com")
+	if !ok {
+		t.Fatalf("expected Lookup to return the expired entry")
+	}
+	if e.Fresh(time.Now()) || e.ETag != `"abc"` || string(e.Value) != "testdata" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if _, err := os.Stat(disk.path("https://example.com")); err != nil {
+		t.Errorf("expected the expired file to be kept: %v", err)
+	}
+
+	cache.AddEntry("https://example.com", Entry{Value: e.Value, ETag: e.ETag})
+	if _, ok := cache.Get("https://example.com"); !ok {
+		t.Errorf("expected the revalidated entry to be fresh")
+	}
+}
+
+func TestDiskTTLOutlivesMemoryInterval(t *testing.T) {
+	disk, err := NewDiskStore(t.TempDir(), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disk.Add("https://example.com", []byte("testdata"), time.Now().Add(-10*time.Minute))
+
+	cache := NewCacheWithDisk(time.Minute, disk)
+	defer cache.Close()
+	e, ok := cache.Lookup("https://example.com")
+	if !ok || !e.Fresh(time.Now()) {
+		t.Fatalf("expected an entry within the disk ttl to be fresh, got %+v", e)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// Once the memory copy expires the entry is read from disk again.
+	cache.reap(time.Now().Add(2 * time.Minute))
+	if _, ok := cache.Get("https://example.com"); !ok {
+		t.Errorf("expected the entry to be read from disk again")
+	}
+}
+
+func TestDiskRemovesEntriesPastRetention(t *testing.T) {
+	disk, err := NewDiskStore(t.TempDir(), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disk.Add("https://example.com", []byte("testdata"), time.Now().Add(-3*time.Hour))
+
+	cache := NewCacheWithDisk(time.Minute, disk, WithStaleRetention(time.Hour))
+	defer cache.Close()
+	if _, ok := cache.Lookup("https://example.com"); ok {
+		t.Errorf("expected an entry past its retention to be missing")
+	}
+	if _, err := os.Stat(disk.path("https://example.com")); !os.IsNotExist(err) {
+		t.Errorf("expected the file to be removed")
+	}
+}
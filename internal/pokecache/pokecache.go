@@ -1,24 +1,134 @@
 package pokecache
 
 import (
+	"container/list"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
+// Cache is an in-memory cache of responses. Entries expire after their TTL
+// and, when limits are set, the least recently used entries are evicted to
+// stay within them. Expired entries can be kept around for a while longer so
+// they can be revalidated instead of downloaded again. Copies of a Cache
+// share the same entries.
 type Cache struct {
-	cache map[string]cacheEntry
-	mux   *sync.Mutex
+	cache      map[string]*list.Element
+	lru        *list.List
+	bytes      *int64
+	counters   *counters
+	mux        *sync.Mutex
+	stop       chan struct{}
+	stopOnce   *sync.Once
+	disk       *DiskStore
+	ttl        time.Duration
+	staleFor   time.Duration
+	maxEntries int
+	maxBytes   int64
 }
 
+// Entry is a cached value along with the HTTP validators it was served
+// with.
+type Entry struct {
+	Value        []byte
+	ETag         string
+	LastModified string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// Fresh reports whether the entry can be used without revalidating it.
+func (e Entry) Fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// cacheEntry is an Entry in the LRU list, which needs its key to remove it
+// from the map when it is evicted.
 type cacheEntry struct {
-	createdAt time.Time
-	val       []byte
+	key string
+	Entry
+}
+
+// size is what an entry counts against the byte budget.
+func (e *cacheEntry) size() int64 {
+	return int64(len(e.key) + len(e.Value) + len(e.ETag) + len(e.LastModified))
+}
+
+// counters are guarded by Cache.mux.
+type counters struct {
+	hits, misses, evictions, expirations int64
+}
+
+// Stats describes how a cache has been used since it was created.
+type Stats struct {
+	// Hits counts lookups answered from memory or disk.
+	Hits int64 `json:"hits"`
+	// Misses counts lookups that found nothing.
+	Misses int64 `json:"misses"`
+	// Evictions counts entries dropped to stay within the size limits.
+	Evictions int64 `json:"evictions"`
+	// Expirations counts entries dropped because they expired.
+	Expirations int64 `json:"expirations"`
+	Entries     int   `json:"entries"`
+	// Bytes is the total size of the keys, values and validators held in
+	// memory.
+	Bytes int64 `json:"bytes"`
+	// MaxEntries and MaxBytes are the cache's limits, or 0 without one.
+	MaxEntries int   `json:"max_entries,omitempty"`
+	MaxBytes   int64 `json:"max_bytes,omitempty"`
+}
+
+// HitRate is the fraction of lookups that were hits, or 0 before any.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Option configures a Cache created by NewCache.
+type Option func(*Cache)
+
+// WithMaxEntries caps the number of entries held in memory.
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+// WithMaxBytes caps the total size of the keys, values and validators held
+// in memory. Values larger than the whole budget are not kept in memory.
+func WithMaxBytes(n int64) Option {
+	return func(c *Cache) {
+		c.maxBytes = n
+	}
+}
+
+// WithStaleRetention keeps expired entries for d before they are removed,
+// so Lookup can still return them for revalidation.
+func WithStaleRetention(d time.Duration) Option {
+	return func(c *Cache) {
+		c.staleFor = d
+	}
 }
 
-func NewCache(interval time.Duration) Cache {
+// NewCache returns a cache whose entries live for interval unless added
+// with their own TTL. Expired entries are removed every interval by a
+// goroutine that runs until Close is called.
+func NewCache(interval time.Duration, opts ...Option) Cache {
 	c := Cache{
-		cache: make(map[string]cacheEntry),
-		mux:   &sync.Mutex{},
+		cache:    make(map[string]*list.Element),
+		lru:      list.New(),
+		bytes:    new(int64),
+		counters: &counters{},
+		mux:      &sync.Mutex{},
+		stop:     make(chan struct{}),
+		stopOnce: &sync.Once{},
+		ttl:      interval,
+	}
+	for _, opt := range opts {
+		opt(&c)
 	}
 
 	go c.reapLoop(interval)
@@ -26,35 +136,246 @@ func NewCache(interval time.Duration) Cache {
 	return c
 }
 
+// NewCacheWithDisk returns a cache that writes entries through to disk and
+// falls back to it on in-memory misses.
+func NewCacheWithDisk(interval time.Duration, disk *DiskStore, opts ...Option) Cache {
+	c := NewCache(interval, opts...)
+	c.disk = disk
+	return c
+}
+
 func (c *Cache) Add(key string, value []byte) {
+	c.AddEntry(key, Entry{Value: value})
+}
+
+// AddTTL adds an entry that expires after ttl instead of the cache's
+// interval.
+func (c *Cache) AddTTL(key string, value []byte, ttl time.Duration) {
+	now := time.Now().UTC()
+	c.AddEntry(key, Entry{Value: value, CreatedAt: now, ExpiresAt: now.Add(ttl)})
+}
+
+// AddEntry adds e under key, replacing any existing entry. A zero
+// CreatedAt means now and a zero ExpiresAt means the cache's interval from
+// now.
+func (c *Cache) AddEntry(key string, e Entry) {
+	now := time.Now().UTC()
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = now
+	}
+	if e.ExpiresAt.IsZero() {
+		e.ExpiresAt = now.Add(c.ttl)
+	}
 	c.mux.Lock()
-	defer c.mux.Unlock()
-	c.cache[key] = cacheEntry{
-		createdAt: time.Now().UTC(),
-		val:       value,
+	c.set(&cacheEntry{key: key, Entry: e})
+	c.mux.Unlock()
+
+	if c.disk != nil {
+		c.disk.AddEntry(key, e)
 	}
 }
 
 func (c *Cache) Get(key string) ([]byte, bool) {
+	e, ok := c.lookup(key, false)
+	return e.Value, ok
+}
+
+// Lookup is like Get but also returns expired entries that are still being
+// retained, or are still on disk, which the caller can tell apart with
+// Entry.Fresh. Returning an expired entry counts as a miss.
+func (c *Cache) Lookup(key string) (Entry, bool) {
+	return c.lookup(key, true)
+}
+
+func (c *Cache) lookup(key string, allowStale bool) (Entry, bool) {
+	now := time.Now().UTC()
+	var e Entry
+	ok := false
+	c.mux.Lock()
+	if el, found := c.cache[key]; found {
+		mem := el.Value.(*cacheEntry)
+		switch {
+		case mem.Fresh(now):
+			c.lru.MoveToFront(el)
+			c.counters.hits++
+			c.mux.Unlock()
+			return mem.Entry, true
+		case c.retained(mem.Entry, now):
+			e, ok = mem.Entry, true
+		default:
+			c.remove(el)
+			c.counters.expirations++
+		}
+	}
+	c.mux.Unlock()
+
+	if c.disk != nil {
+		// Disk entries are fresh for the store's ttl, which usually outlives
+		// the memory interval. The copy kept in memory expires with the
+		// interval so it is read from disk again, not revalidated.
+		if disk, found := c.disk.GetEntry(key); found && (!ok || !disk.CreatedAt.Before(e.CreatedAt)) {
+			if c.retained(disk, now) {
+				e, ok = disk, true
+				disk.ExpiresAt = minTime(disk.ExpiresAt, now.Add(c.ttl))
+				c.mux.Lock()
+				c.set(&cacheEntry{key: key, Entry: disk})
+				c.mux.Unlock()
+			} else {
+				c.disk.Remove(key)
+			}
+		}
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if ok && e.Fresh(now) {
+		c.counters.hits++
+		return e, true
+	}
+	c.counters.misses++
+	if ok && allowStale {
+		return e, true
+	}
+	return Entry{}, false
+}
+
+// Stats returns the cache's counters along with its current size.
+func (c *Cache) Stats() Stats {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return Stats{
+		Hits:        c.counters.hits,
+		Misses:      c.counters.misses,
+		Evictions:   c.counters.evictions,
+		Expirations: c.counters.expirations,
+		Entries:     c.lru.Len(),
+		Bytes:       *c.bytes,
+		MaxEntries:  c.maxEntries,
+		MaxBytes:    c.maxBytes,
+	}
+}
+
+// Keys returns the keys held in memory that start with prefix, sorted.
+func (c *Cache) Keys(prefix string) []string {
 	c.mux.Lock()
 	defer c.mux.Unlock()
-	val, ok := c.cache[key]
-	return val.val, ok
+	keys := []string{}
+	for key := range c.cache {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Remove drops key from memory and disk, reporting whether it was cached.
+func (c *Cache) Remove(key string) bool {
+	c.mux.Lock()
+	el, ok := c.cache[key]
+	if ok {
+		c.remove(el)
+	}
+	c.mux.Unlock()
+
+	if c.disk != nil && c.disk.Remove(key) {
+		ok = true
+	}
+	return ok
+}
+
+// Clear drops every entry from memory and disk and returns how many were
+// held in memory. The counters are kept.
+func (c *Cache) Clear() (int, error) {
+	c.mux.Lock()
+	n := c.lru.Len()
+	clear(c.cache)
+	c.lru.Init()
+	*c.bytes = 0
+	c.mux.Unlock()
+
+	if c.disk != nil {
+		return n, c.disk.Clear()
+	}
+	return n, nil
+}
+
+// set stores e as the most recently used entry and evicts from the back
+// until the cache is within its limits. Values too large to ever fit are
+// not stored. The caller must hold c.mux.
+func (c *Cache) set(e *cacheEntry) {
+	if el, ok := c.cache[e.key]; ok {
+		c.remove(el)
+	}
+	if c.maxBytes > 0 && e.size() > c.maxBytes {
+		return
+	}
+
+	c.cache[e.key] = c.lru.PushFront(e)
+	*c.bytes += e.size()
+	for c.overLimit() {
+		c.remove(c.lru.Back())
+		c.counters.evictions++
+	}
+}
+
+func (c *Cache) overLimit() bool {
+	return (c.maxEntries > 0 && c.lru.Len() > c.maxEntries) ||
+		(c.maxBytes > 0 && *c.bytes > c.maxBytes)
+}
+
+// remove drops an entry from memory. The caller must hold c.mux.
+func (c *Cache) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*cacheEntry)
+	delete(c.cache, e.key)
+	*c.bytes -= e.size()
+}
+
+// Close stops the reaper. Entries already in the cache can still be used,
+// but are no longer removed in the background. Close is safe to call more
+// than once.
+func (c *Cache) Close() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	return nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// retained reports whether e is fresh or expired recently enough to be kept
+// for revalidation.
+func (c *Cache) retained(e Entry, now time.Time) bool {
+	return now.Before(e.ExpiresAt.Add(c.staleFor))
 }
 
 func (c *Cache) reapLoop(interval time.Duration) {
 	ticker := time.NewTicker(interval)
-	for range ticker.C {
-		c.reap(time.Now().UTC(), interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.reap(time.Now().UTC())
+		case <-c.stop:
+			return
+		}
 	}
 }
 
-func (c *Cache) reap(now time.Time, last time.Duration) {
+func (c *Cache) reap(now time.Time) {
 	c.mux.Lock()
 	defer c.mux.Unlock()
-	for k, v := range c.cache {
-		if v.createdAt.Before(now.Add(-last)) {
-			delete(c.cache, k)
+	for el := c.lru.Back(); el != nil; {
+		prev := el.Prev()
+		if !c.retained(el.Value.(*cacheEntry).Entry, now) {
+			c.remove(el)
+			c.counters.expirations++
 		}
+		el = prev
 	}
 }
@@ -2,6 +2,7 @@ package pokecache
 
 import (
 	"fmt"
+	"runtime"
 	"testing"
 	"time"
 )
@@ -25,6 +26,7 @@ func TestAddGet(t *testing.T) {
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
 			cache := NewCache(interval)
+			defer cache.Close()
 			cache.Add(c.key, c.val)
 			val, ok := cache.Get(c.key)
 			if !ok {
@@ -43,6 +45,7 @@ func TestReapLoop(t *testing.T) {
 	const baseTime = 5 * time.Millisecond
 	const waitTime = baseTime + 5*time.Millisecond
 	cache := NewCache(baseTime)
+	defer cache.Close()
 	cache.Add("https://example.com", []byte("testdata"))
 
 	_, ok := cache.Get("https://example.com")
@@ -59,3 +62,193 @@ func TestReapLoop(t *testing.T) {
 		return
 	}
 }
+
+func TestLRUEviction(t *testing.T) {
+	cache := NewCache(time.Minute, WithMaxEntries(2))
+	defer cache.Close()
+	cache.Add("a", []byte("1"))
+	cache.Add("b", []byte("2"))
+	cache.Get("a")
+	cache.Add("c", []byte("3"))
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected the least recently used key to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected to find key %s", key)
+		}
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestByteBudget(t *testing.T) {
+	cache := NewCache(time.Minute, WithMaxBytes(20))
+	defer cache.Close()
+	cache.Add("a", make([]byte, 9))
+	cache.Add("b", make([]byte, 9))
+	if got := cache.Stats().Bytes; got != 20 {
+		t.Errorf("Result: %d, does not equal expected: %d", got, 20)
+	}
+
+	cache.Add("c", make([]byte, 4))
+	if stats := cache.Stats(); stats.Bytes != 15 || stats.Entries != 2 {
+		t.Errorf("expected 2 entries in 15 bytes, got %d in %d", stats.Entries, stats.Bytes)
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("expected a to be evicted")
+	}
+
+	cache.Add("huge", make([]byte, 100))
+	if _, ok := cache.Get("huge"); ok || cache.Stats().Entries != 2 {
+		t.Errorf("expected a value over the budget not to be stored")
+	}
+
+	cache.Add("b", make([]byte, 1))
+	if got := cache.Stats().Bytes; got != 7 {
+		t.Errorf("Result: %d, does not equal expected: %d after replacing b", got, 7)
+	}
+}
+
+func TestAddTTL(t *testing.T) {
+	cache := NewCache(time.Minute)
+	defer cache.Close()
+	cache.AddTTL("short", []byte("testdata"), time.Millisecond)
+	cache.Add("long", []byte("testdata"))
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Errorf("expected short to have expired")
+	}
+	if _, ok := cache.Get("long"); !ok {
+		t.Errorf("expected to find long")
+	}
+	if stats := cache.Stats(); stats.Entries != 1 || stats.Expirations != 1 {
+		t.Errorf("expected the expired entry to be dropped, got %+v", stats)
+	}
+}
+
+// waitForGoroutines polls until at most n goroutines are running, since
+// stopped goroutines take a moment to exit.
+func waitForGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected at most %d goroutines, have %d", n, runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCloseStopsReaper(t *testing.T) {
+	before := runtime.NumGoroutine()
+	caches := []Cache{}
+	for i := 0; i < 50; i++ {
+		caches = append(caches, NewCache(time.Millisecond))
+	}
+	if runtime.NumGoroutine() < before+50 {
+		t.Fatalf("expected a reaper per cache")
+	}
+
+	for _, c := range caches {
+		c.Close()
+		c.Close()
+	}
+	waitForGoroutines(t, before)
+
+	cache := caches[0]
+	cache.Add("https://example.com", []byte("testdata"))
+	if _, ok := cache.Get("https://example.com"); !ok {
+		t.Errorf("expected a closed cache to still serve entries")
+	}
+}
+
+func TestStats(t *testing.T) {
+	const interval = time.Minute
+	cache := NewCache(interval)
+	defer cache.Close()
+	cache.Add("a", []byte("1"))
+	cache.Add("b", []byte("2"))
+	cache.Get("a")
+	cache.Get("c")
+	cache.Get("d")
+	cache.reap(time.Now().UTC().Add(2 * interval))
+	cache.Add("e", []byte("55"))
+
+	got := cache.Stats()
+	expected := Stats{
+		Hits:        1,
+		Misses:      2,
+		Expirations: 2,
+		Entries:     1,
+		Bytes:       3,
+	}
+	if got != expected {
+		t.Errorf("Result: %+v, does not equal expected: %+v", got, expected)
+	}
+	if rate := got.HitRate(); rate < 0.33 || rate > 0.34 {
+		t.Errorf("unexpected hit rate %f", rate)
+	}
+}
+
+func TestKeysRemoveClear(t *testing.T) {
+	cache := NewCache(time.Minute)
+	defer cache.Close()
+	for _, key := range []string{"pokemon/b", "pokemon/a", "move/a"} {
+		cache.Add(key, []byte("testdata"))
+	}
+
+	keys := cache.Keys("pokemon/")
+	if fmt.Sprint(keys) != "[pokemon/a pokemon/b]" {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	if !cache.Remove("pokemon/a") {
+		t.Errorf("expected pokemon/a to be removed")
+	}
+	if cache.Remove("pokemon/a") {
+		t.Errorf("expected pokemon/a to be gone already")
+	}
+
+	n, err := cache.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := cache.Stats(); n != 2 || stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("expected 2 entries cleared, cleared %d and have %d left", n, stats.Entries)
+	}
+}
+
+func TestStaleRetention(t *testing.T) {
+	cache := NewCache(time.Minute, WithStaleRetention(time.Hour))
+	defer cache.Close()
+	cache.AddEntry("https://example.com", Entry{
+		Value:     []byte("testdata"),
+		ETag:      `"abc"`,
+		ExpiresAt: time.Now().Add(-time.Second),
+	})
+
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected Get to skip the expired entry")
+	}
+	e, ok := cache.Lookup("https://example.com")
+	if !ok {
+		t.Fatalf("expected Lookup to return the expired entry")
+	}
+	if e.Fresh(time.Now()) || e.ETag != `"abc"` || string(e.Value) != "testdata" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	cache.reap(time.Now())
+	if cache.Stats().Entries != 1 {
+		t.Errorf("expected the entry to be retained")
+	}
+	cache.reap(time.Now().Add(2 * time.Hour))
+	if cache.Stats().Entries != 0 {
+		t.Errorf("expected the entry to be reaped after the retention period")
+	}
+}
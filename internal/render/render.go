@@ -0,0 +1,125 @@
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	Text  Format = "text"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	CSV   Format = "csv"
+	Table Format = "table"
+)
+
+var Formats = []Format{Text, JSON, YAML, CSV, Table}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q", s)
+}
+
+// Structured reports whether the format is meant for other programs, in
+// which case informational messages should stay off stdout.
+func (f Format) Structured() bool {
+	return f != Text && f != ""
+}
+
+// Texter is implemented by results that have a human readable form.
+type Texter interface {
+	WriteText(w io.Writer) error
+}
+
+// Tabular is implemented by results that can be shown as rows for the csv
+// and table formats.
+type Tabular interface {
+	Header() []string
+	Rows() [][]string
+}
+
+// Render writes v to w in the given format. Results are encoded with their
+// json tags for json and yaml, so both formats share field names and order.
+func Render(w io.Writer, format Format, v any) error {
+	switch format {
+	case Text, "":
+		if t, ok := v.(Texter); ok {
+			return t.WriteText(w)
+		}
+		_, err := fmt.Fprintln(w, v)
+		return err
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		return renderYAML(w, v)
+	case CSV:
+		t, ok := v.(Tabular)
+		if !ok {
+			return fmt.Errorf("output format %s is not supported for this command", format)
+		}
+		cw := csv.NewWriter(w)
+		cw.Write(t.Header())
+		cw.WriteAll(t.Rows())
+		return cw.Error()
+	case Table:
+		t, ok := v.(Tabular)
+		if !ok {
+			return fmt.Errorf("output format %s is not supported for this command", format)
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		header := make([]string, len(t.Header()))
+		for i, h := range t.Header() {
+			header[i] = strings.ToUpper(h)
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, row := range t.Rows() {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// renderYAML goes through JSON so that yaml output uses the same keys as
+// json output. JSON is valid YAML, so decoding it into a node keeps the key
+// order, and clearing the styles turns the flow syntax into block syntax.
+func renderYAML(w io.Writer, v any) error {
+	dat, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(dat, &node); err != nil {
+		return err
+	}
+	clearStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func clearStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		clearStyle(c)
+	}
+}
@@ -0,0 +1,68 @@
+package render
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+type result struct {
+	Name  string   `json:"name"`
+	Flag  string   `json:"flag"`
+	Types []string `json:"types"`
+}
+
+func (r result) WriteText(w io.Writer) error {
+	_, err := io.WriteString(w, r.Name+"\n")
+	return err
+}
+
+func (r result) Header() []string {
+	return []string{"name", "types"}
+}
+
+func (r result) Rows() [][]string {
+	return [][]string{{r.Name, strings.Join(r.Types, " ")}}
+}
+
+func TestRender(t *testing.T) {
+	v := result{Name: "bulbasaur", Flag: "true", Types: []string{"grass", "poison"}}
+	cases := []struct {
+		format   Format
+		expected string
+	}{
+		{format: Text, expected: "bulbasaur\n"},
+		{format: JSON, expected: "{\n  \"name\": \"bulbasaur\",\n  \"flag\": \"true\",\n  \"types\": [\n    \"grass\",\n    \"poison\"\n  ]\n}\n"},
+		{format: YAML, expected: "name: bulbasaur\nflag: \"true\"\ntypes:\n  - grass\n  - poison\n"},
+		{format: CSV, expected: "name,types\nbulbasaur,grass poison\n"},
+		{format: Table, expected: "NAME       TYPES\nbulbasaur  grass poison\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, c.format, v); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != c.expected {
+				t.Errorf("Result:\n%q\ndoes not equal expected:\n%q", buf.String(), c.expected)
+			}
+		})
+	}
+}
+
+func TestRenderTabularOnly(t *testing.T) {
+	if err := Render(io.Discard, CSV, struct{}{}); err == nil {
+		t.Errorf("expected an error for a result without rows")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("JSON"); err != nil || f != JSON {
+		t.Errorf("unexpected result: %v %v", f, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
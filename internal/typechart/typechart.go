@@ -0,0 +1,144 @@
+package typechart
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+// FetchFunc loads a type by name, normally pokeapi.Client.GetTypeContext.
+type FetchFunc func(ctx context.Context, name string) (pokeapi.Type, error)
+
+// Chart answers type effectiveness questions, loading each type's damage
+// relations once and keeping them in memory.
+type Chart struct {
+	fetch FetchFunc
+	types map[string]pokeapi.Type
+	mux   *sync.Mutex
+}
+
+func New(fetch FetchFunc) *Chart {
+	return &Chart{
+		fetch: fetch,
+		types: make(map[string]pokeapi.Type),
+		mux:   &sync.Mutex{},
+	}
+}
+
+// Add stores a type in the chart without fetching it.
+func (c *Chart) Add(t pokeapi.Type) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.types[t.Name] = t
+}
+
+func (c *Chart) get(ctx context.Context, name string) (pokeapi.Type, error) {
+	c.mux.Lock()
+	t, ok := c.types[name]
+	c.mux.Unlock()
+	if ok {
+		return t, nil
+	}
+
+	t, err := c.fetch(ctx, name)
+	if err != nil {
+		return pokeapi.Type{}, err
+	}
+	c.Add(t)
+	return t, nil
+}
+
+// Multiplier returns the damage multiplier of an attacking type against a
+// Pokemon with the defending types, e.g. 4 for ice against dragon/flying.
+// A type given twice only counts once.
+func (c *Chart) Multiplier(ctx context.Context, attacking string, defending ...string) (float64, error) {
+	m := 1.0
+	for _, name := range distinct(defending) {
+		t, err := c.get(ctx, name)
+		if err != nil {
+			return 0, err
+		}
+		m *= relationMultiplier(t.DamageRelations, attacking)
+	}
+	return m, nil
+}
+
+func relationMultiplier(r pokeapi.TypeRelations, attacking string) float64 {
+	switch {
+	case contains(r.NoDamageFrom, attacking):
+		return 0
+	case contains(r.HalfDamageFrom, attacking):
+		return 0.5
+	case contains(r.DoubleDamageFrom, attacking):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Effectiveness is the multiplier of one attacking type.
+type Effectiveness struct {
+	Type       string
+	Multiplier float64
+}
+
+// Defense returns every attacking type that does not deal neutral damage to
+// the defending types, strongest first.
+func (c *Chart) Defense(ctx context.Context, defending ...string) ([]Effectiveness, error) {
+	defending = distinct(defending)
+	attackers := map[string]bool{}
+	for _, name := range defending {
+		t, err := c.get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		r := t.DamageRelations
+		for _, list := range [][]pokeapi.NamedAPIResource{r.NoDamageFrom, r.HalfDamageFrom, r.DoubleDamageFrom} {
+			for _, a := range list {
+				attackers[a.Name] = true
+			}
+		}
+	}
+
+	res := []Effectiveness{}
+	for a := range attackers {
+		m, err := c.Multiplier(ctx, a, defending...)
+		if err != nil {
+			return nil, err
+		}
+		if m != 1 {
+			res = append(res, Effectiveness{Type: a, Multiplier: m})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Multiplier != res[j].Multiplier {
+			return res[i].Multiplier > res[j].Multiplier
+		}
+		return res[i].Type < res[j].Type
+	})
+	return res, nil
+}
+
+// distinct returns names without repeats, keeping the first of each.
+func distinct(names []string) []string {
+	seen := map[string]bool{}
+	res := []string{}
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+func contains(list []pokeapi.NamedAPIResource, name string) bool {
+	for _, r := range list {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
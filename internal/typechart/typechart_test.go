@@ -0,0 +1,104 @@
+package typechart
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+func named(names ...string) []pokeapi.NamedAPIResource {
+	res := []pokeapi.NamedAPIResource{}
+	for _, n := range names {
+		res = append(res, pokeapi.NamedAPIResource{Name: n})
+	}
+	return res
+}
+
+var testTypes = map[string]pokeapi.Type{
+	"dragon": {Name: "dragon", DamageRelations: pokeapi.TypeRelations{
+		DoubleDamageFrom: named("ice", "dragon", "fairy"),
+		HalfDamageFrom:   named("fire", "water", "grass", "electric"),
+	}},
+	"flying": {Name: "flying", DamageRelations: pokeapi.TypeRelations{
+		DoubleDamageFrom: named("ice", "electric", "rock"),
+		HalfDamageFrom:   named("grass", "fighting", "bug"),
+		NoDamageFrom:     named("ground"),
+	}},
+}
+
+func newTestChart(fetches *int) *Chart {
+	return New(func(ctx context.Context, name string) (pokeapi.Type, error) {
+		*fetches++
+		t, ok := testTypes[name]
+		if !ok {
+			return pokeapi.Type{}, fmt.Errorf("unknown type %s", name)
+		}
+		return t, nil
+	})
+}
+
+func TestMultiplier(t *testing.T) {
+	fetches := 0
+	chart := newTestChart(&fetches)
+	cases := []struct {
+		attacking string
+		defending []string
+		expected  float64
+	}{
+		{attacking: "ice", defending: []string{"dragon", "flying"}, expected: 4},
+		{attacking: "ground", defending: []string{"dragon", "flying"}, expected: 0},
+		{attacking: "grass", defending: []string{"dragon", "flying"}, expected: 0.25},
+		{attacking: "electric", defending: []string{"dragon", "flying"}, expected: 1},
+		{attacking: "rock", defending: []string{"flying"}, expected: 2},
+		{attacking: "normal", defending: []string{"dragon"}, expected: 1},
+		{attacking: "ice", defending: []string{"dragon", "dragon"}, expected: 2},
+	}
+
+	for _, c := range cases {
+		m, err := chart.Multiplier(context.Background(), c.attacking, c.defending...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if m != c.expected {
+			t.Errorf("%s against %v: expected %v, got %v", c.attacking, c.defending, c.expected, m)
+		}
+	}
+	if fetches != 2 {
+		t.Errorf("expected each type to be fetched once, got %d fetches", fetches)
+	}
+}
+
+func TestDefense(t *testing.T) {
+	fetches := 0
+	chart := newTestChart(&fetches)
+	res, err := chart.Defense(context.Background(), "dragon", "flying")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) == 0 || res[0].Type != "ice" || res[0].Multiplier != 4 {
+		t.Errorf("expected ice x4 first, got %+v", res)
+	}
+	last := res[len(res)-1]
+	if last.Type != "ground" || last.Multiplier != 0 {
+		t.Errorf("expected ground x0 last, got %+v", last)
+	}
+	for _, e := range res {
+		if e.Type == "electric" {
+			t.Errorf("expected neutral electric to be left out")
+		}
+	}
+}
+
+func TestDefenseIgnoresRepeatedTypes(t *testing.T) {
+	fetches := 0
+	chart := newTestChart(&fetches)
+	res, err := chart.Defense(context.Background(), "flying", "flying")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 7 || res[0].Multiplier != 2 || res[6].Multiplier != 0 {
+		t.Errorf("expected flying's own weaknesses, got %+v", res)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+func commandLearnset(ctx context.Context, cfg *config, params []string) (any, error) {
+	args, flags, err := commandFlags(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, errors.New("usage: learnset <pokemon_name> [--version-group <name>] [--method <name>]")
+	}
+	for name := range flags {
+		if name != "version-group" && name != "method" {
+			return nil, fmt.Errorf("unknown option --%s", name)
+		}
+	}
+
+	pokemon, err := cfg.pokeapiClient.GetPokemonContext(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	versionGroup := flags["version-group"]
+	if versionGroup == "" {
+		versionGroup = latestVersionGroup(pokemon)
+	}
+	method := flags["method"]
+	if method == "" {
+		method = "level-up"
+	}
+
+	res := learnsetResult{
+		Pokemon:      pokemon.Name,
+		VersionGroup: versionGroup,
+		Method:       method,
+		Moves:        []learnsetEntry{},
+	}
+	names := []string{}
+	for _, m := range pokemon.Moves {
+		for _, d := range m.VersionGroupDetails {
+			if d.VersionGroup.Name != versionGroup || d.MoveLearnMethod.Name != method {
+				continue
+			}
+			res.Moves = append(res.Moves, learnsetEntry{Name: m.Move.Name, Level: d.LevelLearnedAt})
+			names = append(names, m.Move.Name)
+		}
+	}
+
+	moves, err := cfg.pokeapiClient.GetMovesContext(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range moves {
+		e := &res.Moves[i]
+		e.Type = m.Type.Name
+		e.DamageClass = m.DamageClass.Name
+		e.Power = m.Power
+		e.Accuracy = m.Accuracy
+		e.PP = m.PP
+	}
+
+	sort.SliceStable(res.Moves, func(i, j int) bool {
+		if res.Moves[i].Level != res.Moves[j].Level {
+			return res.Moves[i].Level < res.Moves[j].Level
+		}
+		return res.Moves[i].Name < res.Moves[j].Name
+	})
+	return res, nil
+}
+
+// latestVersionGroup returns the newest version group the Pokemon has moves
+// in, going by the id at the end of the version group URL.
+func latestVersionGroup(p pokeapi.Pokemon) string {
+	latest, latestID := "", -1
+	for _, m := range p.Moves {
+		for _, d := range m.VersionGroupDetails {
+			if id := pokeapi.ResourceID(d.VersionGroup.URL); id > latestID {
+				latest, latestID = d.VersionGroup.Name, id
+			}
+		}
+	}
+	return latest
+}
+
+type learnsetEntry struct {
+	Name        string `json:"name"`
+	Level       int    `json:"level"`
+	Type        string `json:"type"`
+	DamageClass string `json:"damage_class"`
+	Power       *int   `json:"power"`
+	Accuracy    *int   `json:"accuracy"`
+	PP          *int   `json:"pp"`
+}
+
+type learnsetResult struct {
+	Pokemon      string          `json:"pokemon"`
+	VersionGroup string          `json:"version_group"`
+	Method       string          `json:"method"`
+	Moves        []learnsetEntry `json:"moves"`
+}
+
+func (r learnsetResult) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Moves %s learns by %s in %s:\n", r.Pokemon, r.Method, r.VersionGroup)
+	if len(r.Moves) == 0 {
+		fmt.Fprintf(w, "  none\n")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  Lv\tMove\tType\tClass\tPower\tAcc\tPP")
+	for _, row := range r.Rows() {
+		fmt.Fprintln(tw, "  "+strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (r learnsetResult) Header() []string {
+	return []string{"level", "move", "type", "damage_class", "power", "accuracy", "pp"}
+}
+
+func (r learnsetResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, m := range r.Moves {
+		rows = append(rows, []string{
+			strconv.Itoa(m.Level), m.Name, m.Type, m.DamageClass,
+			optionalInt(m.Power), optionalInt(m.Accuracy), optionalInt(m.PP),
+		})
+	}
+	return rows
+}
+
+func optionalInt(v *int) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.Itoa(*v)
+}
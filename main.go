@@ -1,29 +1,54 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"math/rand"
+	"net"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/rasmussecher/pokedex/internal/battle"
+	"github.com/rasmussecher/pokedex/internal/capture"
 	"github.com/rasmussecher/pokedex/internal/pokeapi"
+	"github.com/rasmussecher/pokedex/internal/pokecache"
+	"github.com/rasmussecher/pokedex/internal/render"
+	"github.com/rasmussecher/pokedex/internal/typechart"
 )
 
 type cliCommand struct {
 	name        string
 	description string
-	callback    func(cfg *config, params []string) error
+	callback    func(ctx context.Context, cfg *config, params []string) (any, error)
+	// keepCase passes the parameters through as typed instead of
+	// lowercasing them, for values that aren't PokeAPI names.
+	keepCase bool
 }
 
 type config struct {
-	pokeapiClient pokeapi.Client
-	caughtPokemon map[string]pokeapi.Pokemon
-	Next          string
-	Previous      string
-	Explore       string
+	pokeapiClient   pokeapi.Client
+	collection      collection
+	pokedex         dexRecord
+	savePath        string
+	rng             *rand.Rand
+	output          render.Format
+	lastAreas       []string
+	region          string
+	location        string
+	area            string
+	regionLocations []string
+	gameVersion     string
+	encounter       *wildEncounter
+	typeChart       *typechart.Chart
+	battle          *battle.Battle
+	battler         int
+	Next            string
+	Previous        string
 }
 
 var commands map[string]cliCommand
@@ -45,14 +70,44 @@ func init() {
 			description: "Show the previous 20 areas",
 			callback:    commandMapb,
 		},
+		"region": {
+			name:        "region [region_name]",
+			description: "List the regions, or travel to one and list its locations",
+			callback:    commandRegion,
+		},
+		"goto": {
+			name:        "goto <location> [area]",
+			description: "Go to a location in the current region",
+			callback:    commandGoto,
+		},
 		"explore": {
-			name:        "explore <area_name>",
-			description: "Explore an area",
+			name:        "explore [area_name]",
+			description: "Explore the current area or the one given",
 			callback:    commandExplore,
 		},
+		"encounter": {
+			name:        "encounter [method]",
+			description: "Look for a wild Pokemon in the current area, walking unless another method is given",
+			callback:    commandEncounter,
+		},
+		"battle": {
+			name:        "battle <pokemon_name>",
+			description: "Send one of your Pokemon to fight the wild Pokemon you encountered",
+			callback:    commandBattle,
+		},
+		"attack": {
+			name:        "attack <move_name|number>",
+			description: "Use a move in the current battle",
+			callback:    commandAttack,
+		},
+		"flee": {
+			name:        "flee",
+			description: "Flee from the current battle or encounter",
+			callback:    commandFlee,
+		},
 		"catch": {
-			name:        "catch <pokemon_name>",
-			description: "Attempt to catch a Pokemon",
+			name:        "catch [pokemon_name] [ball]",
+			description: "Attempt to catch the wild Pokemon you encountered",
 			callback:    commandCatch,
 		},
 		"inspect": {
@@ -60,11 +115,79 @@ func init() {
 			description: "Inspect a Pokemon in your inventory",
 			callback:    commandInspect,
 		},
+		"evolution": {
+			name:        "evolution <pokemon_name>",
+			description: "Show how a Pokemon evolves",
+			callback:    commandEvolution,
+		},
+		"evolve": {
+			name:        "evolve <pokemon_name> [trade] [item]",
+			description: "Evolve a caught Pokemon that meets its evolution conditions",
+			callback:    commandEvolve,
+		},
+		"learnset": {
+			name:        "learnset <pokemon_name> [--version-group <name>] [--method <name>]",
+			description: "List the moves a Pokemon learns, by level",
+			callback:    commandLearnset,
+		},
+		"matchup": {
+			name:        "matchup <pokemon|type> [type2]",
+			description: "Show the weaknesses, resistances and immunities of a Pokemon or type combination",
+			callback:    commandMatchup,
+		},
+		"party": {
+			name:        "party",
+			description: "List the Pokemon in your party",
+			callback:    commandParty,
+		},
+		"box": {
+			name:        "box [number]",
+			description: "List your PC boxes, or the Pokemon in one of them",
+			callback:    commandBox,
+		},
+		"deposit": {
+			name:        "deposit <pokemon> [box]",
+			description: "Move a party Pokemon to a PC box",
+			callback:    commandDeposit,
+		},
+		"withdraw": {
+			name:        "withdraw <pokemon>",
+			description: "Move a Pokemon from the PC to your party",
+			callback:    commandWithdraw,
+		},
+		"release": {
+			name:        "release <pokemon>",
+			description: "Release a caught Pokemon back into the wild",
+			callback:    commandRelease,
+		},
+		"nickname": {
+			name:        "nickname <pokemon> [nickname]",
+			description: "Give a caught Pokemon a nickname, or clear it",
+			callback:    commandNickname,
+			keepCase:    true,
+		},
 		"pokedex": {
-			name:        "pokedex",
-			description: "Print all the Pokemons in your Pokedex",
+			name:        "pokedex [--completion] [--missing] [--region <name> | --generation <name>]",
+			description: "Show seen and caught Pokemon, completion, or what is still missing",
 			callback:    commandPokedex,
 		},
+		"cache": {
+			name:        "cache stats | clear | list [prefix] | evict <url>",
+			description: "Show cache statistics, or list, evict or clear cached responses",
+			callback:    commandCache,
+		},
+		"save": {
+			name:        "save [file]",
+			description: "Save your caught Pokemon and map position",
+			callback:    commandSave,
+			keepCase:    true,
+		},
+		"load": {
+			name:        "load [file]",
+			description: "Load a previously saved game",
+			callback:    commandLoad,
+			keepCase:    true,
+		},
 		"exit": {
 			name:        "exit",
 			description: "Exit the Pokedex",
@@ -74,147 +197,303 @@ func init() {
 }
 
 func main() {
-	pokeClient := pokeapi.NewClient(5*time.Second, 5*time.Minute)
+	apiBase := flag.String("api-base", envOr("POKEDEX_API_BASE", pokeapi.DefaultBaseURL), "base URL of the PokeAPI instance to use (env POKEDEX_API_BASE)")
+	cacheDir := flag.String("cache-dir", defaultCacheDir(), "directory for the persistent response cache, empty to disable")
+	cacheMemory := flag.Int64("cache-memory", 32<<20, "bytes of responses to keep in memory, 0 for no limit")
+	serveStale := flag.Duration("stale-while-revalidate", 0, "how long after expiring a cached response may be shown while it is refreshed in the background")
+	rateLimit := flag.Float64("rate-limit", 20, "most requests per second to send to PokeAPI, 0 for no limit")
+	retries := flag.Int("retries", pokeapi.DefaultRetries, "times to retry a request that failed with a server error, rate limit or timeout")
+	savePath := flag.String("save-file", defaultSavePath(), "file used to save and restore your Pokedex, empty to disable")
+	historyPath := flag.String("history-file", defaultHistoryPath(), "file used to keep command history, empty to disable")
+	output := flag.String("output", string(render.Text), "output format: text, json, yaml, csv or table")
+	jsonOutput := flag.Bool("json", false, "shorthand for --output json")
+	gameVersion := flag.String("game-version", "", "game version used for wild encounters, e.g. red or crystal")
+	flag.Usage = usage
+	flag.Parse()
 
-	ctx := config{
-		pokeapiClient: pokeClient,
-		caughtPokemon: map[string]pokeapi.Pokemon{},
-		Next:          "https://pokeapi.co/api/v2/location-area",
-		Previous:      "https://pokeapi.co/api/v2/location-area",
-		Explore:       "https://pokeapi.co/api/v2/location-area/",
-	}
-
-	scanner := bufio.NewScanner(os.Stdin)
-	for {
-		fmt.Print("Pokedex > ")
-		scanner.Scan()
-		input := cleanInput(scanner.Text())
-		if len(input) == 0 {
-			fmt.Printf("You must input a command. Type \"help\" for a list of commands")
-			continue
-		}
-		command, ok := commands[input[0]]
-		if ok {
-			command.callback(&ctx, input[1:])
+	format, err := render.ParseFormat(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *jsonOutput {
+		format = render.JSON
+	}
+
+	cacheOpts := []pokecache.Option{
+		pokecache.WithMaxBytes(*cacheMemory),
+		pokecache.WithStaleRetention(max(pokeapi.DefaultStaleRetention, *serveStale)),
+	}
+	clientOpts := []pokeapi.Option{
+		pokeapi.WithBaseURL(*apiBase),
+		pokeapi.WithTimeout(5 * time.Second),
+		pokeapi.WithCacheInterval(5 * time.Minute),
+		pokeapi.WithCacheOptions(cacheOpts...),
+		pokeapi.WithStaleWhileRevalidate(*serveStale),
+		pokeapi.WithRetries(*retries),
+		pokeapi.WithRateLimit(*rateLimit, max(int(*rateLimit), 1)),
+	}
+	if *cacheDir != "" {
+		disk, err := pokecache.NewDiskStore(*cacheDir, 24*time.Hour, 50<<20)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not open cache directory, using memory only: %v\n", err)
 		} else {
-			fmt.Print("Unknown command\n")
+			clientOpts = append(clientOpts, pokeapi.WithCache(pokecache.NewCacheWithDisk(5*time.Minute, disk, cacheOpts...)))
 		}
 	}
+	pokeClient := pokeapi.NewClient(clientOpts...)
+
+	cfg := config{
+		pokeapiClient: pokeClient,
+		savePath:      *savePath,
+		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		output:        format,
+		gameVersion:   *gameVersion,
+		Next:          pokeClient.Endpoint("location-area"),
+		Previous:      pokeClient.Endpoint("location-area"),
+	}
+	cfg.typeChart = typechart.New(cfg.pokeapiClient.GetTypeContext)
+	if cfg.savePath != "" {
+		if err := loadState(&cfg, cfg.savePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Could not load save file: %v\n", err)
+		}
+	}
+
+	args := flag.Args()
+	code := 0
+	switch {
+	case len(args) > 0 && args[0] == "run":
+		code = runScriptFiles(&cfg, args[1:])
+	case len(args) > 0:
+		code = runOneShot(&cfg, args)
+	case !isTerminal(os.Stdin):
+		code = runScript(&cfg, os.Stdin, "stdin")
+	default:
+		startRepl(&cfg, *historyPath)
+		autosave(&cfg)
+	}
+	// Close waits for background refreshes, so they reach the cache before
+	// the process exits.
+	cfg.pokeapiClient.Close()
+	os.Exit(code)
+}
+
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex")
 }
 
-func cleanInput(text string) []string {
-	words := strings.Fields(text)
-	for i := range words {
-		words[i] = strings.ToLower(words[i])
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
-	return words
+	return fallback
 }
 
-func commandHelp(cfg *config, params []string) error {
-	fmt.Print("Welcome to the Pokedex!\n")
-	fmt.Print("Usage:\n\n")
+func commandHelp(ctx context.Context, cfg *config, params []string) (any, error) {
+	res := helpResult{Commands: []commandInfo{}}
 	for _, c := range commands {
-		fmt.Printf("%s, %s\n", c.name, c.description)
+		res.Commands = append(res.Commands, commandInfo{Usage: c.name, Description: c.description})
 	}
-	return nil
+	sort.Slice(res.Commands, func(i, j int) bool {
+		return res.Commands[i].Usage < res.Commands[j].Usage
+	})
+	return res, nil
 }
 
-func commandMap(cfg *config, params []string) error {
-	handleMap(cfg, cfg.Next)
-	return nil
+func commandMap(ctx context.Context, cfg *config, params []string) (any, error) {
+	return handleMap(ctx, cfg, cfg.Next)
 }
 
-func commandMapb(cfg *config, params []string) error {
-	handleMap(cfg, cfg.Previous)
-	return nil
+func commandMapb(ctx context.Context, cfg *config, params []string) (any, error) {
+	return handleMap(ctx, cfg, cfg.Previous)
 }
 
-func commandExplore(cfg *config, params []string) error {
-	if len(params) < 1 {
-		fmt.Printf("You must enter an area!\n")
-		return nil
+func commandExplore(ctx context.Context, cfg *config, params []string) (any, error) {
+	area := cfg.area
+	if len(params) > 0 {
+		area = params[0]
+	}
+	if area == "" {
+		return nil, errors.New("you are not anywhere yet, use \"goto <location>\" or \"explore <area_name>\"")
 	}
-	area := params[0]
-	encounters := cfg.pokeapiClient.GetPokemonsForArea(cfg.Explore + area)
-	for _, e := range encounters.Encounters {
-		fmt.Printf("%s\n", e.Pokemon.Name)
+	encounters, err := cfg.pokeapiClient.GetPokemonsForAreaContext(ctx, area)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	version := pickVersion(encounters, cfg.gameVersion)
+	seeArea(ctx, cfg, encounters, version)
+	return newExploreResult(encounters, version), nil
 }
 
-func commandCatch(cfg *config, params []string) error {
-	if len(params) != 1 {
-		return errors.New("you must provide a pokemon name")
+func commandCatch(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) > 2 {
+		return nil, errors.New("usage: catch [pokemon_name] [poke-ball|great-ball|ultra-ball|master-ball]")
+	}
+
+	wild := cfg.encounter
+	if wild == nil {
+		return nil, errors.New("there is no wild Pokemon here, use \"encounter\" to look for one")
 	}
 
-	name := params[0]
-	pokemon, err := cfg.pokeapiClient.GetPokemon(name)
+	ball := capture.PokeBall
+	for _, param := range params {
+		if b, ok := capture.Balls[param]; ok {
+			ball = b
+			continue
+		}
+		if strings.HasSuffix(param, "-ball") {
+			return nil, fmt.Errorf("unknown ball %q", param)
+		}
+		if param != wild.Pokemon {
+			return nil, fmt.Errorf("there is no wild %s here, only %s", param, wild.Pokemon)
+		}
+	}
+
+	if cfg.collection.full() {
+		return nil, errors.New("your party and PC boxes are full, release some Pokemon first")
+	}
+
+	pokemon, err := cfg.pokeapiClient.GetPokemonContext(ctx, wild.Pokemon)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	species, err := cfg.pokeapiClient.GetPokemonSpeciesContext(ctx, pokemon.Species.Name)
+	if err != nil {
+		return nil, err
 	}
 
-	res := rand.Intn(pokemon.BaseExperience)
+	stats, err := wildStats(ctx, cfg, wild, pokemon)
+	if err != nil {
+		return nil, err
+	}
+	target := capture.Target{
+		CaptureRate: species.CaptureRate,
+		MaxHP:       stats.HP,
+		CurrentHP:   stats.HP,
+	}
+	if cfg.battle != nil {
+		target.MaxHP = cfg.battle.Opponent.Stats.HP
+		target.CurrentHP = cfg.battle.Opponent.HP
+	}
 
-	fmt.Printf("Throwing a Pokeball at %s...\n", pokemon.Name)
-	if res > 40 {
-		fmt.Printf("%s escaped!\n", pokemon.Name)
-		return nil
+	attempt := capture.Attempt(cfg.rng, target, ball)
+	res := catchResult{
+		Pokemon: pokemon.Name,
+		Ball:    ball.Name,
+		Shakes:  attempt.Shakes,
+		Caught:  attempt.Caught,
+	}
+	if !attempt.Caught {
+		return res, nil
 	}
 
-	fmt.Printf("%s was caught!\n", pokemon.Name)
+	growth, err := cfg.pokeapiClient.GetGrowthRateContext(ctx, species.GrowthRate.Name)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.battle != nil {
+		res.Experience, err = gainExperience(ctx, cfg, cfg.battler, pokemon, wild.Level)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	cfg.caughtPokemon[pokemon.Name] = pokemon
-	return nil
+	cfg.encounter = nil
+	cfg.battle = nil
+	owned, box, err := cfg.collection.add(ownedPokemon{
+		Pokemon:    pokemon,
+		Level:      wild.Level,
+		Experience: growth.ExperienceAt(wild.Level),
+		IVs:        wild.IVs,
+		Nature:     wild.Nature,
+		CaughtAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.ID = owned.ID
+	res.Box = box
+	cfg.pokedex.catch(species.Name, species.ID)
+	autosave(cfg)
+	return res, nil
 }
 
-func commandInspect(cfg *config, params []string) error {
+func commandInspect(ctx context.Context, cfg *config, params []string) (any, error) {
 	if len(params) != 1 {
-		return errors.New("you must provide a pokemon name")
+		return nil, errors.New("you must provide a pokemon name")
 	}
 
-	name := params[0]
-	pokemon, ok := cfg.caughtPokemon[name]
-	if !ok {
-		fmt.Printf("you have not cought that pokemon\n")
+	pokemon, _, err := cfg.collection.find(params[0])
+	if err != nil {
+		return nil, err
 	}
-	printPokemon(pokemon)
-	return nil
-}
 
-func commandPokedex(cfg *config, params []string) error {
-	fmt.Printf("Your Pokedex:\n")
-	for _, p := range cfg.caughtPokemon {
-		fmt.Printf(" - %s\n", p.Name)
+	species, err := cfg.pokeapiClient.GetPokemonSpeciesContext(ctx, pokemon.Pokemon.Species.Name)
+	if err != nil {
+		return nil, err
+	}
+	growth, err := cfg.pokeapiClient.GetGrowthRateContext(ctx, species.GrowthRate.Name)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := pokemon.stats(ctx, cfg)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return newInspectResult(*pokemon, species, growth, stats), nil
 }
 
-func commandExit(cfg *config, params []string) error {
-	fmt.Print("Closing the Pokedex... Goodbye!")
+func commandExit(ctx context.Context, cfg *config, params []string) (any, error) {
+	autosave(cfg)
+	notice(cfg, "Closing the Pokedex... Goodbye!\n")
+	cfg.pokeapiClient.Close()
 	os.Exit(0)
-	return nil
+	return nil, nil
 }
 
-func handleMap(cfg *config, url string) {
+func handleMap(ctx context.Context, cfg *config, url string) (any, error) {
 	if url == "" {
-		fmt.Printf("You must go further forward in the pagination.\n")
-		return
+		notice(cfg, "You must go further forward in the pagination.\n")
+		return nil, nil
+	}
+	location, err := cfg.pokeapiClient.GetListContext(ctx, url)
+	if err != nil {
+		return nil, err
 	}
-	location := cfg.pokeapiClient.GetList(url)
 	cfg.Next = location.Next
 	cfg.Previous = location.Previous
-	for _, m := range location.ExtractNames() {
-		fmt.Printf("%s\n", m)
-	}
+	cfg.lastAreas = location.ExtractNames()
+	return areaListResult{
+		Areas:    cfg.lastAreas,
+		Next:     location.Next,
+		Previous: location.Previous,
+	}, nil
 }
 
-func printPokemon(p pokeapi.Pokemon) {
-	fmt.Printf("Name: %s\nHeight: %d\nWeight: %d\nStats:\n", p.Name, p.Height, p.Weight)
-	for _, s := range p.Stats {
-		fmt.Printf("  -%s: %d\n", s.Stat.Name, s.BaseStat)
-	}
-	fmt.Printf("Types:\n")
-	for _, t := range p.Types {
-		fmt.Printf("  - %s\n", t.Type.Name)
+func describeError(err error) string {
+	var notFound *pokeapi.NotFoundError
+	var rateLimited *pokeapi.RateLimitError
+	var server *pokeapi.ServerError
+	var decode *pokeapi.DecodeError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "Request cancelled."
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "PokeAPI is taking too long to answer, try again later."
+	case errors.As(err, &notFound):
+		return "Nothing found by that name, check the spelling and try again."
+	case errors.As(err, &rateLimited):
+		return "PokeAPI is rate limiting us, wait a moment and try again."
+	case errors.As(err, &server):
+		return "PokeAPI is having trouble right now, try again later."
+	case errors.As(err, &decode):
+		return "PokeAPI sent a response we could not understand."
+	default:
+		return fmt.Sprintf("Error: %v", err)
 	}
 }
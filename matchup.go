@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+func commandMatchup(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) < 1 || len(params) > 2 {
+		return nil, errors.New("usage: matchup <pokemon|type> [type2]")
+	}
+	if len(params) == 2 && params[0] == params[1] {
+		return nil, fmt.Errorf("%s is given twice, a Pokemon has at most one of each type", params[0])
+	}
+
+	res := matchupResult{Types: params}
+	if len(params) == 1 {
+		types, name, err := resolveTypes(ctx, cfg, params[0])
+		if err != nil {
+			return nil, err
+		}
+		res.Pokemon = name
+		res.Types = types
+	}
+
+	effectiveness, err := cfg.typeChart.Defense(ctx, res.Types...)
+	if err != nil {
+		return nil, err
+	}
+	res.Weaknesses = []typeMultiplier{}
+	res.Resistances = []typeMultiplier{}
+	res.Immunities = []string{}
+	for _, e := range effectiveness {
+		switch {
+		case e.Multiplier == 0:
+			res.Immunities = append(res.Immunities, e.Type)
+		case e.Multiplier > 1:
+			res.Weaknesses = append(res.Weaknesses, typeMultiplier{Type: e.Type, Multiplier: e.Multiplier})
+		default:
+			res.Resistances = append(res.Resistances, typeMultiplier{Type: e.Type, Multiplier: e.Multiplier})
+		}
+	}
+	return res, nil
+}
+
+// resolveTypes treats name as a type, falling back to the types of the
+// Pokemon with that name.
+func resolveTypes(ctx context.Context, cfg *config, name string) ([]string, string, error) {
+	t, err := cfg.pokeapiClient.GetTypeContext(ctx, name)
+	if err == nil {
+		cfg.typeChart.Add(t)
+		return []string{t.Name}, "", nil
+	}
+	var notFound *pokeapi.NotFoundError
+	if !errors.As(err, &notFound) {
+		return nil, "", err
+	}
+
+	pokemon, err := cfg.pokeapiClient.GetPokemonContext(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+	types := []string{}
+	for _, t := range pokemon.Types {
+		types = append(types, t.Type.Name)
+	}
+	return types, pokemon.Name, nil
+}
+
+type typeMultiplier struct {
+	Type       string  `json:"type"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+type matchupResult struct {
+	Pokemon     string           `json:"pokemon,omitempty"`
+	Types       []string         `json:"types"`
+	Weaknesses  []typeMultiplier `json:"weaknesses"`
+	Resistances []typeMultiplier `json:"resistances"`
+	Immunities  []string         `json:"immunities"`
+}
+
+func (r matchupResult) WriteText(w io.Writer) error {
+	if r.Pokemon != "" {
+		fmt.Fprintf(w, "%s (%s)\n", r.Pokemon, strings.Join(r.Types, "/"))
+	} else {
+		fmt.Fprintf(w, "%s\n", strings.Join(r.Types, "/"))
+	}
+	fmt.Fprintf(w, "Weak to:\n")
+	for _, m := range r.Weaknesses {
+		fmt.Fprintf(w, "  - %s x%s\n", m.Type, formatMultiplier(m.Multiplier))
+	}
+	fmt.Fprintf(w, "Resists:\n")
+	for _, m := range r.Resistances {
+		fmt.Fprintf(w, "  - %s x%s\n", m.Type, formatMultiplier(m.Multiplier))
+	}
+	fmt.Fprintf(w, "Immune to:\n")
+	for _, t := range r.Immunities {
+		fmt.Fprintf(w, "  - %s\n", t)
+	}
+	return nil
+}
+
+func (r matchupResult) Header() []string {
+	return []string{"attacking_type", "multiplier"}
+}
+
+func (r matchupResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, m := range r.Weaknesses {
+		rows = append(rows, []string{m.Type, formatMultiplier(m.Multiplier)})
+	}
+	for _, m := range r.Resistances {
+		rows = append(rows, []string{m.Type, formatMultiplier(m.Multiplier)})
+	}
+	for _, t := range r.Immunities {
+		rows = append(rows, []string{t, "0"})
+	}
+	return rows
+}
+
+func formatMultiplier(m float64) string {
+	return strconv.FormatFloat(m, 'f', -1, 64)
+}
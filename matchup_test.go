@@ -0,0 +1,12 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMatchupRejectsRepeatedType(t *testing.T) {
+	if _, err := commandMatchup(context.Background(), &config{}, []string{"fire", "fire"}); err == nil {
+		t.Errorf("expected an error for a repeated type")
+	}
+}
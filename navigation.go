@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func commandRegion(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) == 0 {
+		regions, err := cfg.pokeapiClient.GetListContext(ctx, cfg.pokeapiClient.Endpoint("region"))
+		if err != nil {
+			return nil, err
+		}
+		return regionListResult{Current: cfg.region, Regions: regions.ExtractNames()}, nil
+	}
+
+	if cfg.battle != nil {
+		return nil, errors.New("you can't travel during a battle")
+	}
+	region, err := cfg.pokeapiClient.GetRegionContext(ctx, params[0])
+	if err != nil {
+		return nil, err
+	}
+	if cfg.region != region.Name {
+		cfg.location = ""
+		cfg.area = ""
+		cfg.encounter = nil
+	}
+	cfg.region = region.Name
+
+	res := regionResult{Name: region.Name, Generation: region.MainGeneration.Name, Locations: []string{}}
+	for _, l := range region.Locations {
+		res.Locations = append(res.Locations, l.Name)
+	}
+	cfg.regionLocations = res.Locations
+	return res, nil
+}
+
+func commandGoto(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) < 1 || len(params) > 2 {
+		return nil, errors.New("usage: goto <location> [area]")
+	}
+	if cfg.battle != nil {
+		return nil, errors.New("you can't travel during a battle")
+	}
+
+	location, err := cfg.pokeapiClient.GetLocationContext(ctx, params[0])
+	if err != nil {
+		return nil, err
+	}
+	if cfg.region != "" && location.Region.Name != cfg.region {
+		return nil, fmt.Errorf("%s is not in %s, travel there with \"region %s\" first", location.Name, cfg.region, location.Region.Name)
+	}
+
+	res := gotoResult{Region: location.Region.Name, Location: location.Name, Areas: []string{}}
+	for _, a := range location.Areas {
+		res.Areas = append(res.Areas, a.Name)
+	}
+
+	switch {
+	case len(params) == 2:
+		area, ok := matchArea(res.Areas, location.Name, params[1])
+		if !ok {
+			return nil, fmt.Errorf("%s has no area called %s", location.Name, params[1])
+		}
+		res.Area = area
+	case len(res.Areas) > 0:
+		res.Area = res.Areas[0]
+	}
+
+	if res.Area != cfg.area {
+		// The wild Pokemon stays behind in the area it was found in.
+		cfg.encounter = nil
+	}
+	cfg.region = res.Region
+	cfg.location = res.Location
+	cfg.area = res.Area
+	return res, nil
+}
+
+// matchArea finds an area by its full name or by the part after the
+// location name, so "goto mt-moon b1f" finds "mt-moon-b1f".
+func matchArea(areas []string, location, name string) (string, bool) {
+	for _, a := range areas {
+		if a == name || a == location+"-"+name {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+type regionListResult struct {
+	Current string   `json:"current,omitempty"`
+	Regions []string `json:"regions"`
+}
+
+func (r regionListResult) WriteText(w io.Writer) error {
+	for _, name := range r.Regions {
+		marker := "  "
+		if name == r.Current {
+			marker = "* "
+		}
+		fmt.Fprintf(w, "%s%s\n", marker, name)
+	}
+	return nil
+}
+
+func (r regionListResult) Header() []string {
+	return []string{"region"}
+}
+
+func (r regionListResult) Rows() [][]string {
+	return column(r.Regions)
+}
+
+type regionResult struct {
+	Name       string   `json:"name"`
+	Generation string   `json:"generation"`
+	Locations  []string `json:"locations"`
+}
+
+func (r regionResult) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Welcome to %s! Locations you can travel to:\n", r.Name)
+	for _, l := range r.Locations {
+		fmt.Fprintf(w, " - %s\n", l)
+	}
+	return nil
+}
+
+func (r regionResult) Header() []string {
+	return []string{"location"}
+}
+
+func (r regionResult) Rows() [][]string {
+	return column(r.Locations)
+}
+
+type gotoResult struct {
+	Region   string   `json:"region"`
+	Location string   `json:"location"`
+	Area     string   `json:"area,omitempty"`
+	Areas    []string `json:"areas"`
+}
+
+func (r gotoResult) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "You arrive at %s in %s.\n", r.Location, r.Region)
+	if r.Area == "" {
+		fmt.Fprintf(w, "There is nowhere to look for wild Pokemon here.\n")
+		return nil
+	}
+	fmt.Fprintf(w, "You are in %s.\n", r.Area)
+	if len(r.Areas) > 1 {
+		fmt.Fprintf(w, "Other areas: %s\n", strings.Join(r.Areas, ", "))
+	}
+	return nil
+}
+
+func (r gotoResult) Header() []string {
+	return []string{"region", "location", "area"}
+}
+
+func (r gotoResult) Rows() [][]string {
+	return [][]string{{r.Region, r.Location, r.Area}}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rasmussecher/pokedex/internal/battle"
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+	"github.com/rasmussecher/pokedex/internal/pokecache"
+)
+
+// newNavigationConfig returns a config whose client answers a few region
+// and location lookups from its cache.
+func newNavigationConfig() *config {
+	cache := pokecache.NewCache(time.Minute)
+	responses := map[string]string{
+		"region/kanto":         `{"name": "kanto", "main_generation": {"name": "generation-i"}, "locations": [{"name": "pallet-town"}, {"name": "mt-moon"}]}`,
+		"region/sinnoh":        `{"name": "sinnoh", "main_generation": {"name": "generation-iv"}, "locations": [{"name": "eterna-city"}]}`,
+		"location/mt-moon":     `{"name": "mt-moon", "region": {"name": "kanto"}, "areas": [{"name": "mt-moon-1f"}, {"name": "mt-moon-b1f"}]}`,
+		"location/eterna-city": `{"name": "eterna-city", "region": {"name": "sinnoh"}, "areas": [{"name": "eterna-city-area"}]}`,
+	}
+	for path, body := range responses {
+		cache.Add("https://pokeapi.test/api/v2/"+path, []byte(body))
+	}
+	client := pokeapi.NewClient(pokeapi.WithBaseURL("https://pokeapi.test/api/v2"), pokeapi.WithCache(cache))
+	return &config{pokeapiClient: client}
+}
+
+func TestMatchArea(t *testing.T) {
+	areas := []string{"mt-moon-1f", "mt-moon-b1f"}
+	cases := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{name: "mt-moon-b1f", expected: "mt-moon-b1f", ok: true},
+		{name: "b1f", expected: "mt-moon-b1f", ok: true},
+		{name: "b2f"},
+	}
+
+	for _, c := range cases {
+		area, ok := matchArea(areas, "mt-moon", c.name)
+		if area != c.expected || ok != c.ok {
+			t.Errorf("Result: %q %v, does not equal expected: %q %v", area, ok, c.expected, c.ok)
+		}
+	}
+}
+
+func TestRegionAndGoto(t *testing.T) {
+	ctx := context.Background()
+	cfg := newNavigationConfig()
+
+	if _, err := commandRegion(ctx, cfg, []string{"kanto"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.region != "kanto" || len(cfg.regionLocations) != 2 {
+		t.Errorf("unexpected region: %s %v", cfg.region, cfg.regionLocations)
+	}
+
+	if _, err := commandGoto(ctx, cfg, []string{"mt-moon"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.location != "mt-moon" || cfg.area != "mt-moon-1f" {
+		t.Errorf("expected the first area of mt-moon, got %s %s", cfg.location, cfg.area)
+	}
+	if _, err := commandGoto(ctx, cfg, []string{"mt-moon", "b1f"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.area != "mt-moon-b1f" {
+		t.Errorf("Result: %s, does not equal expected: mt-moon-b1f", cfg.area)
+	}
+	if _, err := commandGoto(ctx, cfg, []string{"mt-moon", "b2f"}); err == nil {
+		t.Error("expected an error for an unknown area")
+	}
+	if _, err := commandGoto(ctx, cfg, []string{"eterna-city"}); err == nil {
+		t.Error("expected an error for a location in another region")
+	}
+	if cfg.location != "mt-moon" || cfg.area != "mt-moon-b1f" {
+		t.Errorf("a failed goto moved to %s %s", cfg.location, cfg.area)
+	}
+
+	if _, err := commandRegion(ctx, cfg, []string{"sinnoh"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.location != "" || cfg.area != "" {
+		t.Errorf("changing region kept location %s %s", cfg.location, cfg.area)
+	}
+	if _, err := commandGoto(ctx, cfg, []string{"eterna-city"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNoTravelDuringBattle(t *testing.T) {
+	ctx := context.Background()
+	cfg := newNavigationConfig()
+	cfg.region = "kanto"
+	cfg.location = "mt-moon"
+	cfg.area = "mt-moon-1f"
+	cfg.battle = &battle.Battle{}
+
+	if _, err := commandGoto(ctx, cfg, []string{"mt-moon", "b1f"}); err == nil {
+		t.Error("expected an error travelling during a battle")
+	}
+	if _, err := commandRegion(ctx, cfg, []string{"sinnoh"}); err == nil {
+		t.Error("expected an error changing region during a battle")
+	}
+	if cfg.region != "kanto" || cfg.area != "mt-moon-1f" {
+		t.Errorf("a refused trip moved to %s %s", cfg.region, cfg.area)
+	}
+}
+
+func TestTravelLeavesEncounterBehind(t *testing.T) {
+	ctx := context.Background()
+	cfg := newNavigationConfig()
+	cfg.region = "kanto"
+	cfg.location = "mt-moon"
+	cfg.area = "mt-moon-1f"
+	cfg.encounter = &wildEncounter{}
+
+	if _, err := commandGoto(ctx, cfg, []string{"mt-moon", "1f"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.encounter == nil {
+		t.Error("staying in the same area dropped the encounter")
+	}
+	if _, err := commandGoto(ctx, cfg, []string{"mt-moon", "b1f"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.encounter != nil {
+		t.Error("expected to leave the encounter behind in mt-moon-1f")
+	}
+
+	cfg.encounter = &wildEncounter{}
+	if _, err := commandRegion(ctx, cfg, []string{"sinnoh"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.encounter != nil {
+		t.Error("expected changing region to drop the encounter")
+	}
+}
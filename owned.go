@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rasmussecher/pokedex/internal/battle"
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+// defaultCatchLevel is the level given to Pokemon from save files that
+// predate levels.
+const defaultCatchLevel = 5
+
+// ownedPokemon is a caught Pokemon along with everything that makes it
+// different from others of its species.
+type ownedPokemon struct {
+	ID         int             `json:"id"`
+	Nickname   string          `json:"nickname,omitempty"`
+	Pokemon    pokeapi.Pokemon `json:"pokemon"`
+	Level      int             `json:"level"`
+	Experience int             `json:"experience"`
+	IVs        battle.Stats    `json:"ivs"`
+	EVs        battle.Stats    `json:"evs"`
+	Nature     string          `json:"nature,omitempty"`
+	CaughtAt   time.Time       `json:"caught_at"`
+}
+
+// name is the nickname if there is one, and otherwise the species.
+func (o ownedPokemon) name() string {
+	if o.Nickname != "" {
+		return o.Nickname
+	}
+	return o.Pokemon.Name
+}
+
+// experience returns the Pokemon's total experience, which is never less
+// than its level requires. Save files that predate experience have none.
+func (o ownedPokemon) experience(growth pokeapi.GrowthRate) int {
+	return max(o.Experience, growth.ExperienceAt(o.Level))
+}
+
+func (o ownedPokemon) stats(ctx context.Context, cfg *config) (battle.Stats, error) {
+	nature, err := natureFor(ctx, cfg, o.Nature)
+	if err != nil {
+		return battle.Stats{}, err
+	}
+	return battle.Calculate(baseStats(o.Pokemon), o.IVs, o.EVs, o.Level, nature), nil
+}
+
+func wildStats(ctx context.Context, cfg *config, wild *wildEncounter, p pokeapi.Pokemon) (battle.Stats, error) {
+	nature, err := natureFor(ctx, cfg, wild.Nature)
+	if err != nil {
+		return battle.Stats{}, err
+	}
+	return battle.Calculate(baseStats(p), wild.IVs, battle.Stats{}, wild.Level, nature), nil
+}
+
+func baseStats(p pokeapi.Pokemon) battle.Stats {
+	stats := battle.Stats{}
+	for _, s := range p.Stats {
+		stats.Set(s.Stat.Name, s.BaseStat)
+	}
+	return stats
+}
+
+// effortYield is the effort values gained for defeating p.
+func effortYield(p pokeapi.Pokemon) battle.Stats {
+	evs := battle.Stats{}
+	for _, s := range p.Stats {
+		evs.Set(s.Stat.Name, s.Effort)
+	}
+	return evs
+}
+
+func natureFor(ctx context.Context, cfg *config, name string) (battle.Nature, error) {
+	if name == "" {
+		return battle.Nature{}, nil
+	}
+	n, err := cfg.pokeapiClient.GetNatureContext(ctx, name)
+	if err != nil {
+		return battle.Nature{}, err
+	}
+	return battle.Nature{Name: n.Name, Increased: n.IncreasedStat.Name, Decreased: n.DecreasedStat.Name}, nil
+}
+
+func randomNature(ctx context.Context, cfg *config) (string, error) {
+	natures, err := cfg.pokeapiClient.GetListContext(ctx, cfg.pokeapiClient.Endpoint("nature")+"?limit=100")
+	if err != nil {
+		return "", err
+	}
+	names := natures.ExtractNames()
+	if len(names) == 0 {
+		return "", nil
+	}
+	return names[cfg.rng.Intn(len(names))], nil
+}
+
+func growthRateFor(ctx context.Context, cfg *config, p pokeapi.Pokemon) (pokeapi.GrowthRate, error) {
+	species, err := cfg.pokeapiClient.GetPokemonSpeciesContext(ctx, p.Species.Name)
+	if err != nil {
+		return pokeapi.GrowthRate{}, err
+	}
+	return cfg.pokeapiClient.GetGrowthRateContext(ctx, species.GrowthRate.Name)
+}
+
+type experienceGain struct {
+	Pokemon    string `json:"pokemon"`
+	Gained     int    `json:"gained"`
+	Experience int    `json:"experience"`
+	Level      int    `json:"level"`
+	LeveledUp  bool   `json:"leveled_up"`
+}
+
+func (g experienceGain) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "%s gained %d XP.\n", g.Pokemon, g.Gained)
+	if g.LeveledUp {
+		fmt.Fprintf(w, "%s grew to level %d!\n", g.Pokemon, g.Level)
+	}
+	return nil
+}
+
+// gainExperience rewards the caught Pokemon with the given id for
+// defeating or catching a wild Pokemon at level, with experience and effort
+// values.
+func gainExperience(ctx context.Context, cfg *config, id int, defeated pokeapi.Pokemon, level int) (*experienceGain, error) {
+	owned, _, ok := cfg.collection.byID(id)
+	if !ok {
+		return nil, nil
+	}
+	growth, err := growthRateFor(ctx, cfg, owned.Pokemon)
+	if err != nil {
+		return nil, err
+	}
+
+	gain := &experienceGain{Pokemon: owned.name()}
+	if owned.Level < battle.MaxLevel {
+		gain.Gained = battle.ExperienceYield(defeated.BaseExperience, level)
+	}
+	owned.Experience = owned.experience(growth) + gain.Gained
+	if maxExperience := growth.ExperienceAt(battle.MaxLevel); maxExperience > 0 {
+		owned.Experience = min(owned.Experience, maxExperience)
+	}
+	owned.EVs = battle.AddEVs(owned.EVs, effortYield(defeated))
+	if newLevel := growth.LevelAt(owned.Experience); newLevel > owned.Level {
+		owned.Level = newLevel
+		gain.LeveledUp = true
+	}
+	gain.Experience = owned.Experience
+	gain.Level = owned.Level
+	return gain, nil
+}
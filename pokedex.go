@@ -0,0 +1,388 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+// nationalDex is the Pokedex used when no region is chosen.
+const nationalDex = "national"
+
+// dexRecord is what the player has registered in their Pokedex, mapping
+// names to national Pokedex numbers, or 0 where the number is unknown.
+// Caught species are always seen too. Alternate forms are recorded under
+// their species. Default forms are recorded under their Pokemon name, which
+// is usually the species name; where it isn't, such as deoxys-normal, the
+// national number still ties it to its species.
+type dexRecord struct {
+	Seen   map[string]int `json:"seen"`
+	Caught map[string]int `json:"caught"`
+}
+
+func (d *dexRecord) see(name string, number int) {
+	if d.Seen == nil {
+		d.Seen = map[string]int{}
+	}
+	d.Seen[name] = max(d.Seen[name], number)
+}
+
+func (d *dexRecord) catch(name string, number int) {
+	d.see(name, number)
+	if d.Caught == nil {
+		d.Caught = map[string]int{}
+	}
+	d.Caught[name] = max(d.Caught[name], number)
+}
+
+// seePokemon registers the species of p, so that default forms such as
+// deoxys-normal count as the species every Pokedex lists.
+func (d *dexRecord) seePokemon(p pokeapi.Pokemon) {
+	d.see(p.Species.Name, nationalNumber(p.Species.URL))
+}
+
+// seeArea registers every Pokemon found in an area for the given version.
+// Only alternate forms are looked up, to find their species; one that can't
+// be is left unseen rather than failing the caller.
+func seeArea(ctx context.Context, cfg *config, area pokeapi.PokemonEncounterList, version string) {
+	found := []pokeapi.NamedAPIResource{}
+	for _, e := range area.Encounters {
+		for _, v := range e.VersionDetails {
+			if v.Version.Name == version {
+				found = append(found, e.Pokemon)
+				break
+			}
+		}
+	}
+	for _, r := range found {
+		if number := nationalNumber(r.URL); number > 0 {
+			cfg.pokedex.see(r.Name, number)
+			continue
+		}
+		if p, err := cfg.pokeapiClient.GetPokemonContext(ctx, r.Name); err == nil {
+			cfg.pokedex.seePokemon(p)
+		}
+	}
+}
+
+// dexIndex finds species in one of a dexRecord's maps by name or national
+// number.
+type dexIndex struct {
+	names   map[string]int
+	numbers map[int]bool
+}
+
+func newDexIndex(names map[string]int) dexIndex {
+	idx := dexIndex{names: names, numbers: map[int]bool{}}
+	for _, number := range names {
+		if number > 0 {
+			idx.numbers[number] = true
+		}
+	}
+	return idx
+}
+
+func (idx dexIndex) has(s pokedexEntry) bool {
+	if _, ok := idx.names[s.Name]; ok {
+		return true
+	}
+	return s.Number > 0 && idx.numbers[s.Number]
+}
+
+// nationalNumber returns the national Pokedex number from a Pokemon or
+// species URL. Alternate forms have ids past the national Pokedex and get 0.
+func nationalNumber(url string) int {
+	id := pokeapi.ResourceID(url)
+	if id >= 10000 {
+		return 0
+	}
+	return id
+}
+
+func commandPokedex(ctx context.Context, cfg *config, params []string) (any, error) {
+	args, flags, err := commandFlags(params, "missing", "completion")
+	if err != nil {
+		return nil, err
+	}
+	for name := range flags {
+		if name != "missing" && name != "completion" && name != "region" && name != "generation" {
+			return nil, fmt.Errorf("unknown option --%s", name)
+		}
+	}
+	if len(args) > 0 || (flags["region"] != "" && flags["generation"] != "") {
+		return nil, errors.New("usage: pokedex [--completion] [--missing] [--region <name> | --generation <name>]")
+	}
+
+	switch {
+	case flags["completion"] != "":
+		return dexCompletions(ctx, cfg)
+	case flags["missing"] != "":
+		region := flags["region"]
+		if region == "" && flags["generation"] == "" {
+			region = cfg.region
+		}
+		scope, err := resolveDexScope(ctx, cfg, region, flags["generation"])
+		if err != nil {
+			return nil, err
+		}
+		return newMissingResult(scope, cfg.pokedex), nil
+	case flags["region"] != "" || flags["generation"] != "":
+		scope, err := resolveDexScope(ctx, cfg, flags["region"], flags["generation"])
+		if err != nil {
+			return nil, err
+		}
+		return completionResult{Dexes: []dexCompletion{newDexCompletion(scope, cfg.pokedex)}}, nil
+	}
+
+	res := pokedexResult{
+		Seen:    len(cfg.pokedex.Seen),
+		Caught:  len(cfg.pokedex.Caught),
+		Pokemon: []pokedexEntry{},
+	}
+	for name, number := range cfg.pokedex.Seen {
+		_, caught := cfg.pokedex.Caught[name]
+		res.Pokemon = append(res.Pokemon, pokedexEntry{Number: number, Name: name, Seen: true, Caught: caught})
+	}
+	sortDexEntries(res.Pokemon)
+	return res, nil
+}
+
+// dexScope is a set of species to complete: a regional Pokedex or a
+// generation.
+type dexScope struct {
+	Name    string
+	Species []pokedexEntry
+}
+
+// resolveDexScope returns the generation if one is given, and otherwise the
+// main Pokedex of region, or the national Pokedex without a region.
+func resolveDexScope(ctx context.Context, cfg *config, region, generation string) (dexScope, error) {
+	if generation != "" {
+		g, err := cfg.pokeapiClient.GetGenerationContext(ctx, generation)
+		if err != nil {
+			return dexScope{}, err
+		}
+		scope := dexScope{Name: g.Name, Species: []pokedexEntry{}}
+		for _, s := range g.PokemonSpecies {
+			scope.Species = append(scope.Species, pokedexEntry{Number: nationalNumber(s.URL), Name: s.Name})
+		}
+		return scope, nil
+	}
+
+	dexName := nationalDex
+	if region != "" {
+		r, err := cfg.pokeapiClient.GetRegionContext(ctx, region)
+		if err != nil {
+			return dexScope{}, err
+		}
+		if len(r.Pokedexes) == 0 {
+			return dexScope{}, fmt.Errorf("%s has no regional Pokedex", r.Name)
+		}
+		dexName = r.Pokedexes[0].Name
+	}
+	dex, err := cfg.pokeapiClient.GetPokedexContext(ctx, dexName)
+	if err != nil {
+		return dexScope{}, err
+	}
+	scope := dexScope{Name: dex.Name, Species: []pokedexEntry{}}
+	for _, e := range dex.PokemonEntries {
+		s := e.PokemonSpecies
+		scope.Species = append(scope.Species, pokedexEntry{Number: nationalNumber(s.URL), Name: s.Name})
+	}
+	return scope, nil
+}
+
+// dexCompletions reports completion of every region's main Pokedex and of
+// every generation.
+func dexCompletions(ctx context.Context, cfg *config) (any, error) {
+	res := completionResult{Dexes: []dexCompletion{}}
+
+	regions, err := cfg.pokeapiClient.GetListContext(ctx, cfg.pokeapiClient.Endpoint("region"))
+	if err != nil {
+		return nil, err
+	}
+	for _, region := range regions.ExtractNames() {
+		scope, err := resolveDexScope(ctx, cfg, region, "")
+		if err != nil {
+			return nil, err
+		}
+		res.Dexes = append(res.Dexes, newDexCompletion(scope, cfg.pokedex))
+	}
+
+	generations, err := cfg.pokeapiClient.GetListContext(ctx, cfg.pokeapiClient.Endpoint("generation"))
+	if err != nil {
+		return nil, err
+	}
+	for _, generation := range generations.ExtractNames() {
+		scope, err := resolveDexScope(ctx, cfg, "", generation)
+		if err != nil {
+			return nil, err
+		}
+		res.Dexes = append(res.Dexes, newDexCompletion(scope, cfg.pokedex))
+	}
+	return res, nil
+}
+
+func sortDexEntries(entries []pokedexEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Number != entries[j].Number {
+			// Unknown numbers go last.
+			if entries[i].Number == 0 || entries[j].Number == 0 {
+				return entries[j].Number == 0
+			}
+			return entries[i].Number < entries[j].Number
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+type pokedexEntry struct {
+	Number int    `json:"number"`
+	Name   string `json:"name"`
+	Seen   bool   `json:"seen"`
+	Caught bool   `json:"caught"`
+}
+
+func (e pokedexEntry) status() string {
+	switch {
+	case e.Caught:
+		return "caught"
+	case e.Seen:
+		return "seen"
+	}
+	return "unseen"
+}
+
+func (e pokedexEntry) number() string {
+	if e.Number == 0 {
+		return "#???"
+	}
+	return fmt.Sprintf("#%03d", e.Number)
+}
+
+type pokedexResult struct {
+	Seen    int            `json:"seen"`
+	Caught  int            `json:"caught"`
+	Pokemon []pokedexEntry `json:"pokemon"`
+}
+
+func (r pokedexResult) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Your Pokedex (seen %d, caught %d):\n", r.Seen, r.Caught)
+	for _, p := range r.Pokemon {
+		fmt.Fprintf(w, " %s %-12s %s\n", p.number(), p.Name, p.status())
+	}
+	return nil
+}
+
+func (r pokedexResult) Header() []string {
+	return []string{"number", "name", "status"}
+}
+
+func (r pokedexResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, p := range r.Pokemon {
+		rows = append(rows, []string{strconv.Itoa(p.Number), p.Name, p.status()})
+	}
+	return rows
+}
+
+type dexCompletion struct {
+	Name    string  `json:"name"`
+	Total   int     `json:"total"`
+	Seen    int     `json:"seen"`
+	Caught  int     `json:"caught"`
+	Percent float64 `json:"percent"`
+}
+
+func newDexCompletion(scope dexScope, record dexRecord) dexCompletion {
+	c := dexCompletion{Name: scope.Name, Total: len(scope.Species)}
+	seen, caught := newDexIndex(record.Seen), newDexIndex(record.Caught)
+	for _, s := range scope.Species {
+		if seen.has(s) {
+			c.Seen++
+		}
+		if caught.has(s) {
+			c.Caught++
+		}
+	}
+	if c.Total > 0 {
+		c.Percent = float64(c.Caught) * 100 / float64(c.Total)
+	}
+	return c
+}
+
+type completionResult struct {
+	Dexes []dexCompletion `json:"dexes"`
+}
+
+func (r completionResult) WriteText(w io.Writer) error {
+	for _, d := range r.Dexes {
+		fmt.Fprintf(w, "%-16s caught %4d/%-4d seen %4d  %5.1f%%\n", d.Name, d.Caught, d.Total, d.Seen, d.Percent)
+	}
+	return nil
+}
+
+func (r completionResult) Header() []string {
+	return []string{"pokedex", "total", "seen", "caught", "percent"}
+}
+
+func (r completionResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, d := range r.Dexes {
+		rows = append(rows, []string{
+			d.Name, strconv.Itoa(d.Total), strconv.Itoa(d.Seen), strconv.Itoa(d.Caught),
+			strconv.FormatFloat(d.Percent, 'f', 1, 64),
+		})
+	}
+	return rows
+}
+
+type missingResult struct {
+	Pokedex string         `json:"pokedex"`
+	Missing []pokedexEntry `json:"missing"`
+}
+
+// newMissingResult lists the species in scope that have not been caught,
+// in national Pokedex order.
+func newMissingResult(scope dexScope, record dexRecord) missingResult {
+	res := missingResult{Pokedex: scope.Name, Missing: []pokedexEntry{}}
+	seen, caught := newDexIndex(record.Seen), newDexIndex(record.Caught)
+	for _, s := range scope.Species {
+		if caught.has(s) {
+			continue
+		}
+		s.Seen = seen.has(s)
+		res.Missing = append(res.Missing, s)
+	}
+	sortDexEntries(res.Missing)
+	return res
+}
+
+func (r missingResult) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Still to catch in the %s Pokedex (%d):\n", r.Pokedex, len(r.Missing))
+	for _, p := range r.Missing {
+		if p.Seen {
+			fmt.Fprintf(w, " %s %s (seen)\n", p.number(), p.Name)
+		} else {
+			fmt.Fprintf(w, " %s %s\n", p.number(), p.Name)
+		}
+	}
+	return nil
+}
+
+func (r missingResult) Header() []string {
+	return []string{"number", "name", "status"}
+}
+
+func (r missingResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, p := range r.Missing {
+		rows = append(rows, []string{strconv.Itoa(p.Number), p.Name, p.status()})
+	}
+	return rows
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+func TestNewMissingResult(t *testing.T) {
+	scope := dexScope{Name: "kanto", Species: []pokedexEntry{
+		{Number: 25, Name: "pikachu"},
+		{Number: 16, Name: "pidgey"},
+		{Number: 1, Name: "bulbasaur"},
+		{Number: 19, Name: "rattata"},
+	}}
+	record := dexRecord{}
+	record.see("pidgey", 16)
+	record.catch("rattata", 19)
+
+	res := newMissingResult(scope, record)
+	expected := []string{"bulbasaur:unseen", "pidgey:seen", "pikachu:unseen"}
+	if len(res.Missing) != len(expected) {
+		t.Fatalf("Result: %v, does not equal expected: %v", res.Missing, expected)
+	}
+	for i, p := range res.Missing {
+		if got := p.Name + ":" + p.status(); got != expected[i] {
+			t.Errorf("Result: %s, does not equal expected: %s", got, expected[i])
+		}
+	}
+
+	c := newDexCompletion(scope, record)
+	if c.Total != 4 || c.Seen != 2 || c.Caught != 1 || c.Percent != 25 {
+		t.Errorf("unexpected completion: %+v", c)
+	}
+}
+
+func TestSortDexEntries(t *testing.T) {
+	entries := []pokedexEntry{
+		{Number: 0, Name: "deoxys-attack"},
+		{Number: 25, Name: "pikachu"},
+		{Number: 3, Name: "venusaur"},
+	}
+	sortDexEntries(entries)
+	if entries[0].Number != 3 || entries[1].Number != 25 || entries[2].Number != 0 {
+		t.Errorf("unexpected order: %v", entries)
+	}
+}
+
+func TestSeePokemonUsesSpecies(t *testing.T) {
+	record := dexRecord{}
+	record.seePokemon(pokeapi.Pokemon{
+		Name:    "deoxys-normal",
+		Species: pokeapi.NamedAPIResource{Name: "deoxys", URL: "https://pokeapi.co/api/v2/pokemon-species/386/"},
+	})
+	record.catch("deoxys", 386)
+
+	if len(record.Seen) != 1 || record.Seen["deoxys"] != 386 {
+		t.Errorf("unexpected seen species: %v", record.Seen)
+	}
+}
+
+func TestDefaultFormCountsAsSpecies(t *testing.T) {
+	scope := dexScope{Name: "national", Species: []pokedexEntry{
+		{Number: 385, Name: "jirachi"},
+		{Number: 386, Name: "deoxys"},
+	}}
+	record := dexRecord{}
+	record.see("deoxys-normal", 386)
+
+	c := newDexCompletion(scope, record)
+	if c.Seen != 1 || c.Caught != 0 {
+		t.Errorf("unexpected completion: %+v", c)
+	}
+	res := newMissingResult(scope, record)
+	if len(res.Missing) != 2 || !res.Missing[1].Seen {
+		t.Errorf("expected deoxys to be seen: %+v", res.Missing)
+	}
+}
+
+func TestSeeAreaFetchesOnlyAlternateForms(t *testing.T) {
+	client := pokeapi.NewClient(pokeapi.WithBaseURL("https://pokeapi.test/api/v2"))
+	defer client.Close()
+	cache := client.Cache()
+	cache.Add("https://pokeapi.test/api/v2/pokemon/rotom-wash", []byte(`{
+		"name": "rotom-wash",
+		"species": {"name": "rotom", "url": "https://pokeapi.test/api/v2/pokemon-species/479/"}
+	}`))
+	cfg := &config{pokeapiClient: client}
+
+	inRed := []pokeapi.VersionEncounterDetail{{Version: pokeapi.NamedAPIResource{Name: "red"}}}
+	area := pokeapi.PokemonEncounterList{Encounters: []pokeapi.PokemonEncounter{
+		{Pokemon: pokeapi.NamedAPIResource{Name: "pikachu", URL: "https://pokeapi.test/api/v2/pokemon/25/"}, VersionDetails: inRed},
+		{Pokemon: pokeapi.NamedAPIResource{Name: "rotom-wash", URL: "https://pokeapi.test/api/v2/pokemon/10009/"}, VersionDetails: inRed},
+		{Pokemon: pokeapi.NamedAPIResource{Name: "rotom-heat", URL: "https://pokeapi.test/api/v2/pokemon/10008/"}, VersionDetails: inRed},
+	}}
+
+	// Nothing can be downloaded, so only the cached alternate form resolves.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	seeArea(ctx, cfg, area, "red")
+
+	expected := map[string]int{"pikachu": 25, "rotom": 479}
+	if len(cfg.pokedex.Seen) != len(expected) {
+		t.Fatalf("Result: %v, does not equal expected: %v", cfg.pokedex.Seen, expected)
+	}
+	for name, number := range expected {
+		if cfg.pokedex.Seen[name] != number {
+			t.Errorf("Result: %v, does not equal expected: %v", cfg.pokedex.Seen, expected)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/peterh/liner"
+	"github.com/rasmussecher/pokedex/internal/render"
+)
+
+func startRepl(cfg *config, historyPath string) {
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	line.SetTabCompletionStyle(liner.TabPrints)
+	line.SetWordCompleter(func(text string, pos int) (string, []string, string) {
+		return completeInput(cfg, text, pos)
+	})
+
+	if historyPath != "" {
+		if f, err := os.Open(historyPath); err == nil {
+			line.ReadHistory(f)
+			f.Close()
+		}
+	}
+
+	for {
+		text, err := line.Prompt(prompt(cfg))
+		if errors.Is(err, liner.ErrPromptAborted) {
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				fmt.Printf("Error reading input: %v\n", err)
+			}
+			fmt.Println()
+			return
+		}
+
+		input := cleanInput(text)
+		if len(input) == 0 {
+			fmt.Printf("You must input a command. Type \"help\" for a list of commands\n")
+			continue
+		}
+		line.AppendHistory(text)
+		writeHistory(line, historyPath)
+
+		command, ok := commands[input[0]]
+		if ok {
+			if err := runCommand(cfg, command, input[1:]); err != nil {
+				fmt.Println(describeError(err))
+			}
+		} else {
+			fmt.Print("Unknown command\n")
+		}
+	}
+}
+
+func prompt(cfg *config) string {
+	if cfg.location != "" {
+		return fmt.Sprintf("Pokedex (%s) > ", cfg.location)
+	}
+	return "Pokedex > "
+}
+
+// runCommand executes a command with a context that is cancelled on SIGINT,
+// so Ctrl-C aborts a slow request instead of killing the REPL. Outside of a
+// command the default signal behavior applies again.
+func runCommand(cfg *config, command cliCommand, params []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	res, err := command.callback(ctx, cfg, params)
+	if err != nil || res == nil {
+		return err
+	}
+	return render.Render(os.Stdout, cfg.output, res)
+}
+
+// notice prints an informational message. With a structured output format
+// it goes to stderr so stdout stays parseable.
+func notice(cfg *config, format string, args ...any) {
+	w := os.Stdout
+	if cfg.output.Structured() {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, format, args...)
+}
+
+// cleanInput splits text into words and lowercases them, except for the
+// parameters of commands that keep their case, such as file paths.
+func cleanInput(text string) []string {
+	words := strings.Fields(text)
+	for i := range words {
+		if i > 0 && commands[words[0]].keepCase {
+			break
+		}
+		words[i] = strings.ToLower(words[i])
+	}
+	return words
+}
+
+// ownedCompletions are the commands whose argument is a caught Pokemon.
+var ownedCompletions = map[string]bool{
+	"inspect":   true,
+	"evolve":    true,
+	"evolution": true,
+	"battle":    true,
+	"deposit":   true,
+	"withdraw":  true,
+	"release":   true,
+	"nickname":  true,
+}
+
+// completeInput completes the word under the cursor: command names first,
+// then caught Pokemon for commands that take one and area names from the
+// last map page for explore. pos counts runes, as liner passes it.
+func completeInput(cfg *config, text string, pos int) (string, []string, string) {
+	runes := []rune(text)
+	head, tail := string(runes[:pos]), string(runes[pos:])
+	start := strings.LastIndexAny(head, " \t") + 1
+	prefix := strings.ToLower(head[start:])
+	words := strings.Fields(head[:start])
+
+	candidates := []string{}
+	switch {
+	case len(words) == 0:
+		for name := range commands {
+			candidates = append(candidates, name+" ")
+		}
+	case len(words) == 1 && ownedCompletions[words[0]]:
+		candidates = append(candidates, cfg.collection.names()...)
+	case len(words) == 1 && words[0] == "explore":
+		candidates = append(candidates, cfg.lastAreas...)
+	case len(words) == 1 && words[0] == "goto":
+		candidates = append(candidates, cfg.regionLocations...)
+	case len(words) == 1 && words[0] == "cache":
+		candidates = append(candidates, "stats ", "clear ", "list ", "evict ")
+	}
+
+	completions := []string{}
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c), prefix) {
+			completions = append(completions, c)
+		}
+	}
+	sort.Strings(completions)
+	return head[:start], completions, tail
+}
+
+func defaultHistoryPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "history")
+}
+
+func writeHistory(line *liner.State, path string) {
+	if path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	line.WriteHistory(f)
+}
@@ -1,6 +1,10 @@
 package main
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
 
 func TestCleanInput(t *testing.T) {
 	cases := []struct {
@@ -19,6 +23,10 @@ func TestCleanInput(t *testing.T) {
 			input:    "",
 			expected: []string{},
 		},
+		{
+			input:    "SAVE ~/Games/Red.json",
+			expected: []string{"save", "~/Games/Red.json"},
+		},
 	}
 
 	for _, c := range cases {
@@ -32,3 +40,45 @@ func TestCleanInput(t *testing.T) {
 		}
 	}
 }
+
+func TestCompleteInput(t *testing.T) {
+	cfg := &config{
+		collection: collection{Party: []ownedPokemon{
+			{Pokemon: pokeapi.Pokemon{Name: "pikachu"}},
+			{Pokemon: pokeapi.Pokemon{Name: "pidgey"}},
+			{Pokemon: pokeapi.Pokemon{Name: "bulbasaur"}, Nickname: "Bulby"},
+			{Pokemon: pokeapi.Pokemon{Name: "eevee"}, Nickname: "Éclair"},
+		}},
+		lastAreas: []string{"canalave-city-area", "eterna-city-area"},
+	}
+
+	cases := []struct {
+		input    string
+		head     string
+		expected []string
+	}{
+		{input: "insp", head: "", expected: []string{"inspect "}},
+		{input: "inspect pi", head: "inspect ", expected: []string{"pidgey", "pikachu"}},
+		{input: "inspect b", head: "inspect ", expected: []string{"Bulby"}},
+		{input: "explore ete", head: "explore ", expected: []string{"eterna-city-area"}},
+		{input: "cache e", head: "cache ", expected: []string{"evict "}},
+		{input: "map x", head: "map ", expected: []string{}},
+		{input: "nickname Éc", head: "nickname ", expected: []string{"Éclair"}},
+	}
+
+	for _, c := range cases {
+		head, completions, tail := completeInput(cfg, c.input, len([]rune(c.input)))
+		if head != c.head || tail != "" {
+			t.Errorf("Result head: %q tail: %q, does not equal expected: %q", head, tail, c.head)
+		}
+		if len(completions) != len(c.expected) {
+			t.Errorf("Result: %v, does not equal expected: %v", completions, c.expected)
+			continue
+		}
+		for i := range completions {
+			if completions[i] != c.expected[i] {
+				t.Errorf("Result: %v, does not equal expected: %v", completions, c.expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rasmussecher/pokedex/internal/battle"
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+type commandInfo struct {
+	Usage       string `json:"usage"`
+	Description string `json:"description"`
+}
+
+type helpResult struct {
+	Commands []commandInfo `json:"commands"`
+}
+
+func (r helpResult) WriteText(w io.Writer) error {
+	fmt.Fprint(w, "Welcome to the Pokedex!\n")
+	fmt.Fprint(w, "Usage:\n\n")
+	for _, c := range r.Commands {
+		fmt.Fprintf(w, "%s, %s\n", c.Usage, c.Description)
+	}
+	return nil
+}
+
+func (r helpResult) Header() []string {
+	return []string{"usage", "description"}
+}
+
+func (r helpResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, c := range r.Commands {
+		rows = append(rows, []string{c.Usage, c.Description})
+	}
+	return rows
+}
+
+type areaListResult struct {
+	Areas    []string `json:"areas"`
+	Next     string   `json:"next,omitempty"`
+	Previous string   `json:"previous,omitempty"`
+}
+
+func (r areaListResult) WriteText(w io.Writer) error {
+	for _, a := range r.Areas {
+		fmt.Fprintf(w, "%s\n", a)
+	}
+	return nil
+}
+
+func (r areaListResult) Header() []string {
+	return []string{"area"}
+}
+
+func (r areaListResult) Rows() [][]string {
+	return column(r.Areas)
+}
+
+type catchResult struct {
+	Pokemon    string          `json:"pokemon"`
+	Ball       string          `json:"ball"`
+	Shakes     int             `json:"shakes"`
+	Caught     bool            `json:"caught"`
+	ID         int             `json:"id,omitempty"`
+	Box        int             `json:"box,omitempty"`
+	Experience *experienceGain `json:"experience,omitempty"`
+}
+
+func (r catchResult) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Throwing a %s at %s...\n", r.Ball, r.Pokemon)
+	for i := 0; i < r.Shakes && i < 3; i++ {
+		fmt.Fprintf(w, "...shake...\n")
+	}
+	if !r.Caught {
+		fmt.Fprintf(w, "%s escaped!\n", r.Pokemon)
+		return nil
+	}
+	fmt.Fprintf(w, "%s was caught!\n", r.Pokemon)
+	if r.Box > 0 {
+		fmt.Fprintf(w, "Your party is full, %s was sent to box %d.\n", r.Pokemon, r.Box)
+	}
+	if r.Experience != nil {
+		r.Experience.WriteText(w)
+	}
+	return nil
+}
+
+func (r catchResult) Header() []string {
+	return []string{"pokemon", "ball", "shakes", "caught"}
+}
+
+func (r catchResult) Rows() [][]string {
+	return [][]string{{r.Pokemon, r.Ball, strconv.Itoa(r.Shakes), strconv.FormatBool(r.Caught)}}
+}
+
+type statValue struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+	Base  int    `json:"base"`
+	IV    int    `json:"iv"`
+	EV    int    `json:"ev"`
+}
+
+type speciesInfo struct {
+	Genus         string   `json:"genus,omitempty"`
+	Generation    string   `json:"generation"`
+	CaptureRate   int      `json:"capture_rate"`
+	BaseHappiness int      `json:"base_happiness"`
+	GrowthRate    string   `json:"growth_rate"`
+	FemaleRatio   *float64 `json:"female_ratio"`
+	EggGroups     []string `json:"egg_groups"`
+	IsLegendary   bool     `json:"is_legendary"`
+	IsMythical    bool     `json:"is_mythical"`
+	FlavorText    string   `json:"flavor_text,omitempty"`
+}
+
+type inspectResult struct {
+	OwnedID    int         `json:"owned_id"`
+	Name       string      `json:"name"`
+	Nickname   string      `json:"nickname,omitempty"`
+	ID         int         `json:"id"`
+	Level      int         `json:"level"`
+	Experience int         `json:"experience"`
+	NextLevel  int         `json:"next_level"`
+	Nature     string      `json:"nature,omitempty"`
+	CaughtAt   time.Time   `json:"caught_at"`
+	Height     int         `json:"height"`
+	Weight     int         `json:"weight"`
+	Stats      []statValue `json:"stats"`
+	Types      []string    `json:"types"`
+	Species    speciesInfo `json:"species"`
+}
+
+func newInspectResult(owned ownedPokemon, s pokeapi.PokemonSpecies, growth pokeapi.GrowthRate, stats battle.Stats) inspectResult {
+	p := owned.Pokemon
+	res := inspectResult{
+		OwnedID:    owned.ID,
+		Name:       p.Name,
+		Nickname:   owned.Nickname,
+		ID:         p.ID,
+		Level:      owned.Level,
+		Experience: owned.experience(growth),
+		NextLevel:  growth.ExperienceAt(owned.Level + 1),
+		Nature:     owned.Nature,
+		CaughtAt:   owned.CaughtAt,
+		Height:     p.Height,
+		Weight:     p.Weight,
+		Stats:      []statValue{},
+		Types:      []string{},
+		Species: speciesInfo{
+			Genus:         s.Genus("en"),
+			Generation:    s.Generation.Name,
+			CaptureRate:   s.CaptureRate,
+			BaseHappiness: s.BaseHappiness,
+			GrowthRate:    s.GrowthRate.Name,
+			EggGroups:     []string{},
+			IsLegendary:   s.IsLegendary,
+			IsMythical:    s.IsMythical,
+			FlavorText:    s.FlavorText("en"),
+		},
+	}
+	for _, st := range p.Stats {
+		name := st.Stat.Name
+		res.Stats = append(res.Stats, statValue{
+			Name:  name,
+			Value: stats.Get(name),
+			Base:  st.BaseStat,
+			IV:    owned.IVs.Get(name),
+			EV:    owned.EVs.Get(name),
+		})
+	}
+	for _, t := range p.Types {
+		res.Types = append(res.Types, t.Type.Name)
+	}
+	if ratio := s.FemaleRatio(); ratio >= 0 {
+		res.Species.FemaleRatio = &ratio
+	}
+	for _, e := range s.EggGroups {
+		res.Species.EggGroups = append(res.Species.EggGroups, e.Name)
+	}
+	return res
+}
+
+func (r inspectResult) WriteText(w io.Writer) error {
+	if r.Nickname != "" {
+		fmt.Fprintf(w, "Name: %s (%s)\n", r.Nickname, r.Name)
+	} else {
+		fmt.Fprintf(w, "Name: %s\n", r.Name)
+	}
+	fmt.Fprintf(w, "Owned id: #%d\nLevel: %d\n", r.OwnedID, r.Level)
+	if r.NextLevel > 0 {
+		fmt.Fprintf(w, "Experience: %d (next level at %d)\n", r.Experience, r.NextLevel)
+	} else {
+		fmt.Fprintf(w, "Experience: %d\n", r.Experience)
+	}
+	if r.Nature != "" {
+		fmt.Fprintf(w, "Nature: %s\n", r.Nature)
+	}
+	fmt.Fprintf(w, "Height: %d\nWeight: %d\nStats:\n", r.Height, r.Weight)
+	for _, s := range r.Stats {
+		fmt.Fprintf(w, "  -%s: %d (base %d, IV %d, EV %d)\n", s.Name, s.Value, s.Base, s.IV, s.EV)
+	}
+	fmt.Fprintf(w, "Types:\n")
+	for _, t := range r.Types {
+		fmt.Fprintf(w, "  - %s\n", t)
+	}
+
+	s := r.Species
+	if s.Genus != "" {
+		fmt.Fprintf(w, "Genus: %s\n", s.Genus)
+	}
+	fmt.Fprintf(w, "Generation: %s\n", s.Generation)
+	fmt.Fprintf(w, "Capture rate: %d\n", s.CaptureRate)
+	fmt.Fprintf(w, "Base happiness: %d\n", s.BaseHappiness)
+	fmt.Fprintf(w, "Growth rate: %s\n", s.GrowthRate)
+	if s.FemaleRatio == nil {
+		fmt.Fprintf(w, "Gender: genderless\n")
+	} else {
+		fmt.Fprintf(w, "Gender: %.1f%% female, %.1f%% male\n", *s.FemaleRatio*100, (1-*s.FemaleRatio)*100)
+	}
+	fmt.Fprintf(w, "Egg groups:\n")
+	for _, e := range s.EggGroups {
+		fmt.Fprintf(w, "  - %s\n", e)
+	}
+	if s.IsLegendary {
+		fmt.Fprintf(w, "Legendary Pokemon\n")
+	}
+	if s.IsMythical {
+		fmt.Fprintf(w, "Mythical Pokemon\n")
+	}
+	if s.FlavorText != "" {
+		fmt.Fprintf(w, "%s\n", s.FlavorText)
+	}
+	return nil
+}
+
+func (r inspectResult) Header() []string {
+	return []string{"field", "value"}
+}
+
+func (r inspectResult) Rows() [][]string {
+	rows := [][]string{
+		{"owned_id", strconv.Itoa(r.OwnedID)},
+		{"name", r.Name},
+		{"nickname", r.Nickname},
+		{"id", strconv.Itoa(r.ID)},
+		{"level", strconv.Itoa(r.Level)},
+		{"experience", strconv.Itoa(r.Experience)},
+		{"nature", r.Nature},
+		{"caught_at", r.CaughtAt.Format(time.RFC3339)},
+		{"height", strconv.Itoa(r.Height)},
+		{"weight", strconv.Itoa(r.Weight)},
+	}
+	for _, s := range r.Stats {
+		rows = append(rows, []string{s.Name, strconv.Itoa(s.Value)})
+	}
+	rows = append(rows,
+		[]string{"types", strings.Join(r.Types, " ")},
+		[]string{"generation", r.Species.Generation},
+		[]string{"capture_rate", strconv.Itoa(r.Species.CaptureRate)},
+		[]string{"growth_rate", r.Species.GrowthRate},
+		[]string{"egg_groups", strings.Join(r.Species.EggGroups, " ")},
+	)
+	return rows
+}
+
+func column(values []string) [][]string {
+	rows := [][]string{}
+	for _, v := range values {
+		rows = append(rows, []string{v})
+	}
+	return rows
+}
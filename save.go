@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rasmussecher/pokedex/internal/battle"
+)
+
+const saveVersion = 3
+
+// saveMigrations upgrade a decoded save file one schema version at a time;
+// saveMigrations[v] turns a version v document into a version v+1 one.
+var saveMigrations = map[int]func(doc map[string]json.RawMessage) error{
+	1: migrateCaughtToStorage,
+	2: migrateStorageToPokedex,
+}
+
+type saveFile struct {
+	Version  int        `json:"version"`
+	SavedAt  time.Time  `json:"saved_at"`
+	Storage  collection `json:"storage"`
+	Pokedex  dexRecord  `json:"pokedex"`
+	Next     string     `json:"next"`
+	Previous string     `json:"previous"`
+	Region   string     `json:"region,omitempty"`
+	Location string     `json:"location,omitempty"`
+	Area     string     `json:"area,omitempty"`
+	// RegionLocations keeps goto completion working after a restart.
+	RegionLocations []string `json:"region_locations,omitempty"`
+	// Encounter lets a one-shot "catch" follow a one-shot "encounter".
+	Encounter *savedEncounter `json:"encounter,omitempty"`
+}
+
+// savedEncounter is a wildEncounter along with the IVs and nature that its
+// own JSON leaves out.
+type savedEncounter struct {
+	wildEncounter
+	IVs    battle.Stats `json:"ivs"`
+	Nature string       `json:"nature"`
+}
+
+func defaultSavePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "save.json")
+}
+
+func saveState(cfg *config, path string) error {
+	if path == "" {
+		return errors.New("no save file configured")
+	}
+
+	save := saveFile{
+		Version:         saveVersion,
+		SavedAt:         time.Now().UTC(),
+		Storage:         cfg.collection,
+		Pokedex:         cfg.pokedex,
+		Next:            cfg.Next,
+		Previous:        cfg.Previous,
+		Region:          cfg.region,
+		Location:        cfg.location,
+		Area:            cfg.area,
+		RegionLocations: cfg.regionLocations,
+	}
+	if cfg.encounter != nil {
+		save.Encounter = &savedEncounter{
+			wildEncounter: *cfg.encounter,
+			IVs:           cfg.encounter.IVs,
+			Nature:        cfg.encounter.Nature,
+		}
+	}
+	dat, err := json.Marshal(save)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, dat, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func loadState(cfg *config, path string) error {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	save, err := decodeSave(dat)
+	if err != nil {
+		return fmt.Errorf("reading save file %s: %w", path, err)
+	}
+
+	cfg.collection = save.Storage
+	cfg.pokedex = save.Pokedex
+	cfg.collection.ensureBoxes()
+	for n := 0; n <= boxCount; n++ {
+		b := *cfg.collection.box(n)
+		for i := range b {
+			if b[i].Level == 0 {
+				b[i].Level = defaultCatchLevel
+			}
+		}
+	}
+	cfg.region = save.Region
+	cfg.location = save.Location
+	cfg.area = save.Area
+	cfg.regionLocations = save.RegionLocations
+	cfg.encounter = nil
+	if e := save.Encounter; e != nil {
+		wild := e.wildEncounter
+		wild.IVs = e.IVs
+		wild.Nature = e.Nature
+		cfg.encounter = &wild
+	}
+	if save.Next != "" || save.Previous != "" {
+		cfg.Next = save.Next
+		cfg.Previous = save.Previous
+	}
+	return nil
+}
+
+// autosave writes the save file if one is configured, reporting but not
+// returning failures so they never abort the command that triggered them.
+func autosave(cfg *config) {
+	if cfg.savePath == "" {
+		return
+	}
+	if err := saveState(cfg, cfg.savePath); err != nil {
+		notice(cfg, "Could not save your progress: %v\n", err)
+	}
+}
+
+func decodeSave(dat []byte) (saveFile, error) {
+	doc := map[string]json.RawMessage{}
+	if err := json.Unmarshal(dat, &doc); err != nil {
+		return saveFile{}, err
+	}
+
+	version := 0
+	if raw, ok := doc["version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return saveFile{}, err
+		}
+	}
+	if version > saveVersion {
+		return saveFile{}, fmt.Errorf("save file version %d is newer than supported version %d", version, saveVersion)
+	}
+	for ; version < saveVersion; version++ {
+		migrate, ok := saveMigrations[version]
+		if !ok {
+			return saveFile{}, fmt.Errorf("no migration from save file version %d", version)
+		}
+		if err := migrate(doc); err != nil {
+			return saveFile{}, fmt.Errorf("migrating save file from version %d: %w", version, err)
+		}
+	}
+
+	dat, err := json.Marshal(doc)
+	if err != nil {
+		return saveFile{}, err
+	}
+	save := saveFile{}
+	if err := json.Unmarshal(dat, &save); err != nil {
+		return saveFile{}, err
+	}
+	return save, nil
+}
+
+// migrateCaughtToStorage moves the flat list of caught Pokemon from
+// version 1 into the party and PC boxes, numbering them in the order they
+// were caught.
+func migrateCaughtToStorage(doc map[string]json.RawMessage) error {
+	caught := []ownedPokemon{}
+	if raw, ok := doc["caught"]; ok {
+		if err := json.Unmarshal(raw, &caught); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(caught, func(i, j int) bool {
+		return caught[i].CaughtAt.Before(caught[j].CaughtAt)
+	})
+
+	storage := collection{}
+	for _, p := range caught {
+		if _, _, err := storage.add(p); err != nil {
+			return err
+		}
+	}
+	dat, err := json.Marshal(storage)
+	if err != nil {
+		return err
+	}
+	doc["storage"] = dat
+	delete(doc, "caught")
+	return nil
+}
+
+// migrateStorageToPokedex registers the species of every stored Pokemon as
+// caught, since version 2 had no Pokedex of its own.
+func migrateStorageToPokedex(doc map[string]json.RawMessage) error {
+	storage := collection{}
+	if raw, ok := doc["storage"]; ok {
+		if err := json.Unmarshal(raw, &storage); err != nil {
+			return err
+		}
+	}
+	dex := dexRecord{}
+	for _, p := range storage.all() {
+		dex.catch(p.Pokemon.Species.Name, nationalNumber(p.Pokemon.Species.URL))
+	}
+	dat, err := json.Marshal(dex)
+	if err != nil {
+		return err
+	}
+	doc["pokedex"] = dat
+	return nil
+}
+
+type saveResult struct {
+	Action string `json:"action"`
+	Path   string `json:"path"`
+	Caught int    `json:"caught"`
+}
+
+func (r saveResult) WriteText(w io.Writer) error {
+	verb := "Saved"
+	prep := "to"
+	if r.Action == "load" {
+		verb, prep = "Loaded", "from"
+	}
+	_, err := fmt.Fprintf(w, "%s %d Pokemon %s %s\n", verb, r.Caught, prep, r.Path)
+	return err
+}
+
+func (r saveResult) Header() []string {
+	return []string{"action", "path", "caught"}
+}
+
+func (r saveResult) Rows() [][]string {
+	return [][]string{{r.Action, r.Path, strconv.Itoa(r.Caught)}}
+}
+
+func commandSave(ctx context.Context, cfg *config, params []string) (any, error) {
+	path := cfg.savePath
+	if len(params) > 0 {
+		path = params[0]
+	}
+	if err := saveState(cfg, path); err != nil {
+		return nil, err
+	}
+	return saveResult{Action: "save", Path: path, Caught: cfg.collection.len()}, nil
+}
+
+func commandLoad(ctx context.Context, cfg *config, params []string) (any, error) {
+	path := cfg.savePath
+	if len(params) > 0 {
+		path = params[0]
+	}
+	if err := loadState(cfg, path); err != nil {
+		return nil, err
+	}
+	return saveResult{Action: "load", Path: path, Caught: cfg.collection.len()}, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rasmussecher/pokedex/internal/battle"
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	caughtAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cfg := &config{
+		Next:            "https://example.com/next",
+		Previous:        "https://example.com/previous",
+		region:          "kanto",
+		regionLocations: []string{"pallet-town", "viridian-forest"},
+		encounter: &wildEncounter{
+			Pokemon: "pikachu",
+			Level:   5,
+			Area:    "viridian-forest-area",
+			IVs:     battle.Stats{HP: 31, Speed: 12},
+			Nature:  "timid",
+		},
+	}
+	cfg.collection.add(ownedPokemon{Pokemon: pokeapi.Pokemon{Name: "pikachu", ID: 25}, CaughtAt: caughtAt})
+	if err := saveState(cfg, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded := &config{}
+	if err := loadState(loaded, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, _, err := loaded.collection.find("pikachu")
+	if err != nil {
+		t.Fatalf("expected pikachu to be loaded: %v", err)
+	}
+	if p.Pokemon.ID != 25 || !p.CaughtAt.Equal(caughtAt) {
+		t.Errorf("unexpected pokemon: %+v", p)
+	}
+	if loaded.Next != cfg.Next || loaded.Previous != cfg.Previous {
+		t.Errorf("unexpected pagination: %s %s", loaded.Next, loaded.Previous)
+	}
+	if loaded.region != "kanto" || len(loaded.regionLocations) != 2 {
+		t.Errorf("unexpected region: %s %v", loaded.region, loaded.regionLocations)
+	}
+	if loaded.encounter == nil || *loaded.encounter != *cfg.encounter {
+		t.Errorf("unexpected encounter: %+v", loaded.encounter)
+	}
+}
+
+func TestDecodeSaveRejectsNewerVersion(t *testing.T) {
+	if _, err := decodeSave([]byte(`{"version": 999}`)); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestMigrateCaughtToStorage(t *testing.T) {
+	caught := ""
+	for i := 0; i < partySize+2; i++ {
+		if i > 0 {
+			caught += ","
+		}
+		caught += fmt.Sprintf(`{"pokemon": {"name": "pidgey", "species": {"name": "pidgey"}}, "caught_at": "2024-01-%02dT00:00:00Z"}`, partySize+2-i)
+	}
+	save, err := decodeSave([]byte(`{"version": 1, "caught": [` + caught + `]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := save.Storage
+	if len(s.Party) != partySize || len(s.Boxes) != boxCount || len(s.Boxes[0]) != 2 {
+		t.Fatalf("unexpected storage: party %d, boxes %d", len(s.Party), len(s.Boxes))
+	}
+	if s.NextID != partySize+3 {
+		t.Errorf("Result: %d, does not equal expected: %d", s.NextID, partySize+3)
+	}
+	if _, ok := save.Pokedex.Caught["pidgey"]; !ok {
+		t.Errorf("expected pidgey to be registered as caught")
+	}
+	// The earliest catch gets the first id.
+	if s.Party[0].ID != 1 || s.Party[0].CaughtAt.Day() != 1 {
+		t.Errorf("unexpected first Pokemon: %+v", s.Party[0])
+	}
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	partySize = 6
+	boxCount  = 12
+	boxSize   = 30
+	// maxNicknameLength is the longest nickname the games allow.
+	maxNicknameLength = 12
+)
+
+// collection holds every caught Pokemon, either in the party or in one of
+// the numbered PC boxes. Box numbers start at 1; 0 stands for the party.
+type collection struct {
+	NextID int              `json:"next_id"`
+	Party  []ownedPokemon   `json:"party"`
+	Boxes  [][]ownedPokemon `json:"boxes"`
+}
+
+func (c *collection) ensureBoxes() {
+	for len(c.Boxes) < boxCount {
+		c.Boxes = append(c.Boxes, []ownedPokemon{})
+	}
+}
+
+// add gives p the next id and stores it in the party, or the first box with
+// room, returning where it went.
+func (c *collection) add(p ownedPokemon) (ownedPokemon, int, error) {
+	c.ensureBoxes()
+	c.NextID = max(c.NextID, 1)
+	p.ID = c.NextID
+	if len(c.Party) < partySize {
+		c.NextID++
+		c.Party = append(c.Party, p)
+		return p, 0, nil
+	}
+	for i := range c.Boxes {
+		if len(c.Boxes[i]) < boxSize {
+			c.NextID++
+			c.Boxes[i] = append(c.Boxes[i], p)
+			return p, i + 1, nil
+		}
+	}
+	return ownedPokemon{}, 0, errors.New("your party and PC boxes are full, release some Pokemon first")
+}
+
+func (c *collection) full() bool {
+	return c.len() >= partySize+boxCount*boxSize
+}
+
+func (c *collection) len() int {
+	n := len(c.Party)
+	for _, b := range c.Boxes {
+		n += len(b)
+	}
+	return n
+}
+
+// all returns the party followed by each box in order.
+func (c *collection) all() []ownedPokemon {
+	res := append([]ownedPokemon{}, c.Party...)
+	for _, b := range c.Boxes {
+		res = append(res, b...)
+	}
+	return res
+}
+
+// box returns the Pokemon in box n, where 0 is the party.
+func (c *collection) box(n int) *[]ownedPokemon {
+	if n == 0 {
+		return &c.Party
+	}
+	c.ensureBoxes()
+	return &c.Boxes[n-1]
+}
+
+func (c *collection) byID(id int) (*ownedPokemon, int, bool) {
+	for n := 0; n <= len(c.Boxes); n++ {
+		b := *c.box(n)
+		for i := range b {
+			if b[i].ID == id {
+				return &b[i], n, true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// find resolves ref, which is an id, a nickname or a species name, to a
+// single Pokemon and the box it is in, ignoring case. The returned pointer
+// is only valid until the collection is next changed.
+func (c *collection) find(ref string) (*ownedPokemon, int, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		if p, n, ok := c.byID(id); ok {
+			return p, n, nil
+		}
+		return nil, 0, fmt.Errorf("you have no Pokemon with id %d", id)
+	}
+
+	ids := []int{}
+	for _, p := range c.all() {
+		if strings.EqualFold(p.Nickname, ref) || (p.Nickname == "" && strings.EqualFold(p.Pokemon.Name, ref)) {
+			ids = append(ids, p.ID)
+		}
+	}
+	if len(ids) == 0 {
+		for _, p := range c.all() {
+			if strings.EqualFold(p.Pokemon.Name, ref) {
+				ids = append(ids, p.ID)
+			}
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return nil, 0, fmt.Errorf("you have not caught %s", ref)
+	case 1:
+		p, n, _ := c.byID(ids[0])
+		return p, n, nil
+	}
+	idList := []string{}
+	for _, id := range ids {
+		idList = append(idList, strconv.Itoa(id))
+	}
+	return nil, 0, fmt.Errorf("you have more than one %s, use an id instead: %s", ref, strings.Join(idList, ", "))
+}
+
+// take removes the Pokemon with the given id from wherever it is stored.
+func (c *collection) take(id int) (ownedPokemon, int, bool) {
+	for n := 0; n <= len(c.Boxes); n++ {
+		b := c.box(n)
+		for i, p := range *b {
+			if p.ID == id {
+				*b = append((*b)[:i], (*b)[i+1:]...)
+				return p, n, true
+			}
+		}
+	}
+	return ownedPokemon{}, 0, false
+}
+
+// names lists how each Pokemon can be referred to, for completion.
+func (c *collection) names() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, p := range c.all() {
+		name := p.name()
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (c *collection) deposit(ref string, box int) (ownedPokemon, error) {
+	p, n, err := c.find(ref)
+	if err != nil {
+		return ownedPokemon{}, err
+	}
+	if n != 0 {
+		return ownedPokemon{}, fmt.Errorf("%s is already in box %d", p.name(), n)
+	}
+	if len(c.Party) == 1 {
+		return ownedPokemon{}, errors.New("you must keep at least one Pokemon in your party")
+	}
+	if len(*c.box(box)) >= boxSize {
+		return ownedPokemon{}, fmt.Errorf("box %d is full", box)
+	}
+	moved, _, _ := c.take(p.ID)
+	b := c.box(box)
+	*b = append(*b, moved)
+	return moved, nil
+}
+
+func (c *collection) withdraw(ref string) (ownedPokemon, int, error) {
+	p, n, err := c.find(ref)
+	if err != nil {
+		return ownedPokemon{}, 0, err
+	}
+	if n == 0 {
+		return ownedPokemon{}, 0, fmt.Errorf("%s is already in your party", p.name())
+	}
+	if len(c.Party) >= partySize {
+		return ownedPokemon{}, 0, errors.New("your party is full, deposit a Pokemon first")
+	}
+	moved, _, _ := c.take(p.ID)
+	c.Party = append(c.Party, moved)
+	return moved, n, nil
+}
+
+func (c *collection) release(ref string) (ownedPokemon, int, error) {
+	p, n, err := c.find(ref)
+	if err != nil {
+		return ownedPokemon{}, 0, err
+	}
+	if n == 0 && len(c.Party) == 1 {
+		return ownedPokemon{}, 0, errors.New("you must keep at least one Pokemon in your party")
+	}
+	released, _, _ := c.take(p.ID)
+	return released, n, nil
+}
+
+// parseBox parses a box number given on the command line.
+func parseBox(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > boxCount {
+		return 0, fmt.Errorf("there is no box %q, boxes are numbered 1 to %d", s, boxCount)
+	}
+	return n, nil
+}
+
+func commandParty(ctx context.Context, cfg *config, params []string) (any, error) {
+	return newStorageResult("Party", 0, partySize, cfg.collection.Party), nil
+}
+
+func commandBox(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) > 1 {
+		return nil, errors.New("usage: box [number]")
+	}
+	cfg.collection.ensureBoxes()
+	if len(params) == 0 {
+		res := boxListResult{Boxes: []boxSummary{}}
+		for i, b := range cfg.collection.Boxes {
+			res.Boxes = append(res.Boxes, boxSummary{Box: i + 1, Count: len(b), Capacity: boxSize})
+		}
+		return res, nil
+	}
+	n, err := parseBox(params[0])
+	if err != nil {
+		return nil, err
+	}
+	return newStorageResult(fmt.Sprintf("Box %d", n), n, boxSize, *cfg.collection.box(n)), nil
+}
+
+func commandDeposit(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) < 1 || len(params) > 2 {
+		return nil, errors.New("usage: deposit <pokemon> [box]")
+	}
+	if cfg.battle != nil {
+		return nil, errors.New("you can't use the PC during a battle")
+	}
+	box := 1
+	if len(params) == 2 {
+		n, err := parseBox(params[1])
+		if err != nil {
+			return nil, err
+		}
+		box = n
+	}
+	p, err := cfg.collection.deposit(params[0], box)
+	if err != nil {
+		return nil, err
+	}
+	autosave(cfg)
+	return transferResult{Action: "deposit", ID: p.ID, Name: p.name(), Box: box}, nil
+}
+
+func commandWithdraw(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) != 1 {
+		return nil, errors.New("usage: withdraw <pokemon>")
+	}
+	if cfg.battle != nil {
+		return nil, errors.New("you can't use the PC during a battle")
+	}
+	p, box, err := cfg.collection.withdraw(params[0])
+	if err != nil {
+		return nil, err
+	}
+	autosave(cfg)
+	return transferResult{Action: "withdraw", ID: p.ID, Name: p.name(), Box: box}, nil
+}
+
+func commandRelease(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) != 1 {
+		return nil, errors.New("usage: release <pokemon>")
+	}
+	if cfg.battle != nil {
+		return nil, errors.New("you can't release Pokemon during a battle")
+	}
+	p, box, err := cfg.collection.release(params[0])
+	if err != nil {
+		return nil, err
+	}
+	autosave(cfg)
+	return transferResult{Action: "release", ID: p.ID, Name: p.name(), Box: box}, nil
+}
+
+func commandNickname(ctx context.Context, cfg *config, params []string) (any, error) {
+	if len(params) < 1 || len(params) > 2 {
+		return nil, errors.New("usage: nickname <pokemon> [nickname]")
+	}
+	p, _, err := cfg.collection.find(params[0])
+	if err != nil {
+		return nil, err
+	}
+
+	nickname := ""
+	if len(params) == 2 {
+		nickname = params[1]
+	}
+	if len(nickname) > maxNicknameLength {
+		return nil, fmt.Errorf("nicknames can be at most %d characters", maxNicknameLength)
+	}
+	if _, err := strconv.Atoi(nickname); err == nil {
+		return nil, errors.New("nicknames can't be numbers, those are used for ids")
+	}
+	p.Nickname = nickname
+	res := transferResult{Action: "nickname", ID: p.ID, Name: p.name()}
+	autosave(cfg)
+	return res, nil
+}
+
+type storedEntry struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Species  string `json:"species"`
+	Level    int    `json:"level"`
+	Nickname string `json:"nickname,omitempty"`
+}
+
+type storageResult struct {
+	Name     string        `json:"name"`
+	Box      int           `json:"box"`
+	Capacity int           `json:"capacity"`
+	Pokemon  []storedEntry `json:"pokemon"`
+}
+
+func newStorageResult(name string, box, capacity int, pokemon []ownedPokemon) storageResult {
+	res := storageResult{Name: name, Box: box, Capacity: capacity, Pokemon: []storedEntry{}}
+	for _, p := range pokemon {
+		res.Pokemon = append(res.Pokemon, storedEntry{
+			ID:       p.ID,
+			Name:     p.name(),
+			Species:  p.Pokemon.Name,
+			Level:    p.Level,
+			Nickname: p.Nickname,
+		})
+	}
+	return res
+}
+
+func (r storageResult) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "%s (%d/%d):\n", r.Name, len(r.Pokemon), r.Capacity)
+	for _, p := range r.Pokemon {
+		if p.Nickname != "" {
+			fmt.Fprintf(w, " #%-4d %s (%s) lv %d\n", p.ID, p.Nickname, p.Species, p.Level)
+		} else {
+			fmt.Fprintf(w, " #%-4d %s lv %d\n", p.ID, p.Species, p.Level)
+		}
+	}
+	return nil
+}
+
+func (r storageResult) Header() []string {
+	return []string{"id", "name", "species", "level"}
+}
+
+func (r storageResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, p := range r.Pokemon {
+		rows = append(rows, []string{strconv.Itoa(p.ID), p.Name, p.Species, strconv.Itoa(p.Level)})
+	}
+	return rows
+}
+
+type boxSummary struct {
+	Box      int `json:"box"`
+	Count    int `json:"count"`
+	Capacity int `json:"capacity"`
+}
+
+type boxListResult struct {
+	Boxes []boxSummary `json:"boxes"`
+}
+
+func (r boxListResult) WriteText(w io.Writer) error {
+	for _, b := range r.Boxes {
+		fmt.Fprintf(w, "Box %-2d %2d/%d\n", b.Box, b.Count, b.Capacity)
+	}
+	return nil
+}
+
+func (r boxListResult) Header() []string {
+	return []string{"box", "count", "capacity"}
+}
+
+func (r boxListResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, b := range r.Boxes {
+		rows = append(rows, []string{strconv.Itoa(b.Box), strconv.Itoa(b.Count), strconv.Itoa(b.Capacity)})
+	}
+	return rows
+}
+
+// transferResult reports a change to a single stored Pokemon. Box is where
+// it was deposited into, withdrawn or released from, with 0 for the party.
+type transferResult struct {
+	Action string `json:"action"`
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Box    int    `json:"box"`
+}
+
+func (r transferResult) WriteText(w io.Writer) error {
+	switch r.Action {
+	case "deposit":
+		fmt.Fprintf(w, "%s (#%d) was stored in box %d.\n", r.Name, r.ID, r.Box)
+	case "withdraw":
+		fmt.Fprintf(w, "%s (#%d) was taken from box %d and joined your party.\n", r.Name, r.ID, r.Box)
+	case "release":
+		fmt.Fprintf(w, "%s (#%d) was released. Bye-bye, %s!\n", r.Name, r.ID, r.Name)
+	case "nickname":
+		fmt.Fprintf(w, "#%d is now called %s.\n", r.ID, r.Name)
+	}
+	return nil
+}
+
+func (r transferResult) Header() []string {
+	return []string{"action", "id", "name", "box"}
+}
+
+func (r transferResult) Rows() [][]string {
+	return [][]string{{r.Action, strconv.Itoa(r.ID), r.Name, strconv.Itoa(r.Box)}}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rasmussecher/pokedex/internal/pokeapi"
+)
+
+func newTestCollection(names ...string) *collection {
+	c := &collection{}
+	for _, name := range names {
+		c.add(ownedPokemon{Pokemon: pokeapi.Pokemon{Name: name}})
+	}
+	return c
+}
+
+func TestCollectionAdd(t *testing.T) {
+	c := newTestCollection("pikachu", "pikachu", "pidgey", "rattata", "caterpie", "weedle")
+	p, box, err := c.add(ownedPokemon{Pokemon: pokeapi.Pokemon{Name: "pikachu"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.ID != 7 || box != 1 {
+		t.Errorf("expected id 7 in box 1, got id %d in box %d", p.ID, box)
+	}
+	if len(c.Party) != partySize || c.len() != 7 {
+		t.Errorf("unexpected sizes: party %d, total %d", len(c.Party), c.len())
+	}
+}
+
+func TestCollectionFind(t *testing.T) {
+	c := newTestCollection("pikachu", "pikachu", "pidgey")
+	c.Party[2].Nickname = "Pidge"
+
+	cases := []struct {
+		ref string
+		id  int
+		err bool
+	}{
+		{ref: "2", id: 2},
+		{ref: "pidge", id: 3},
+		{ref: "PIDGE", id: 3},
+		{ref: "Pikachu", err: true},
+		{ref: "pidgey", id: 3},
+		{ref: "pikachu", err: true},
+		{ref: "9", err: true},
+		{ref: "bulbasaur", err: true},
+	}
+
+	for _, cs := range cases {
+		p, _, err := c.find(cs.ref)
+		if cs.err {
+			if err == nil {
+				t.Errorf("expected an error for %q", cs.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", cs.ref, err)
+			continue
+		}
+		if p.ID != cs.id {
+			t.Errorf("Result: %d, does not equal expected: %d for %q", p.ID, cs.id, cs.ref)
+		}
+	}
+}
+
+func TestCollectionTransfers(t *testing.T) {
+	c := newTestCollection("pikachu", "pidgey")
+
+	if _, err := c.deposit("pikachu", 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Party) != 1 || len(c.Boxes[2]) != 1 {
+		t.Fatalf("expected pikachu in box 3, party %d", len(c.Party))
+	}
+	if _, err := c.deposit("pidgey", 1); err == nil {
+		t.Errorf("expected an error depositing the last party Pokemon")
+	}
+	if _, _, err := c.release("pidgey"); err == nil {
+		t.Errorf("expected an error releasing the last party Pokemon")
+	}
+
+	_, box, err := c.withdraw("1")
+	if err != nil || box != 3 {
+		t.Fatalf("unexpected withdraw: box %d, %v", box, err)
+	}
+	if _, _, err := c.release("pidgey"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.len() != 1 || c.Party[0].Pokemon.Name != "pikachu" {
+		t.Errorf("unexpected collection: %+v", c.Party)
+	}
+}